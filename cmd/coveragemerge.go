@@ -0,0 +1,62 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/palantir/godel-test-plugin/testplugin"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var coverageMergeCmd = &cobra.Command{
+	Use:   "coverage-merge [flags] profiles...",
+	Short: "Merge coverage profiles (such as the profiles written by different partitions) into a single profile",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if coverageMergeOutputFlagVal == "" {
+			return errors.Errorf("--output must be specified")
+		}
+		formats, err := parseCoverageFormats(coverageMergeFormatsFlagVal)
+		if err != nil {
+			return err
+		}
+		// the coverage minimums of the configuration are enforced for the merged profile
+		param, err := testParamFromFlags(testConfigFileFlagVal, godelConfigFileFlagVal)
+		if err != nil {
+			return err
+		}
+		param.CoverageBase = coverageBaseFlagVal
+		return testplugin.MergeCoverageProfiles(projectDirFlagVal, coverageMergeOutputFlagVal, args, formats, param, cmd.OutOrStdout())
+	},
+}
+
+// parseCoverageFormats parses the provided coverage report formats.
+func parseCoverageFormats(formatStrs []string) ([]testplugin.CoverageFormat, error) {
+	var formats []testplugin.CoverageFormat
+	for _, s := range formatStrs {
+		format, err := testplugin.ParseCoverageFormat(s)
+		if err != nil {
+			return nil, err
+		}
+		formats = append(formats, format)
+	}
+	return formats, nil
+}
+
+func init() {
+	coverageMergeCmd.Flags().StringVar(&coverageMergeOutputFlagVal, "output", "", "file to which the merged coverage profile is written")
+	coverageMergeCmd.Flags().StringSliceVar(&coverageMergeFormatsFlagVal, "format", nil, `formats of the coverage reports written to the directory of the output file: "cobertura", "lcov" and/or "html"`)
+	coverageMergeCmd.Flags().StringVar(&coverageBaseFlagVal, "coverage-base", "", "git revision relative to which the coverage of the changed lines of the merged profile is reported")
+	RootCmd.AddCommand(coverageMergeCmd)
+}
@@ -0,0 +1,37 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/palantir/godel-test-plugin/testplugin"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var junitMergeCmd = &cobra.Command{
+	Use:   "junit-merge [flags] reports...",
+	Short: "Merge JUnit reports (such as the reports written by different partitions) into a single report",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if junitMergeOutputFlagVal == "" {
+			return errors.Errorf("--output must be specified")
+		}
+		return testplugin.MergeJUnitReports(junitMergeOutputFlagVal, args, cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	junitMergeCmd.Flags().StringVar(&junitMergeOutputFlagVal, "output", "", "file to which the merged JUnit report is written")
+	RootCmd.AddCommand(junitMergeCmd)
+}
@@ -0,0 +1,51 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/palantir/godel-test-plugin/testplugin"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Print the packages that match the provided tags assigned to every partition",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		param, err := testParamFromFlags(testConfigFileFlagVal, godelConfigFileFlagVal)
+		if err != nil {
+			return err
+		}
+		if planPartitionsFlagVal < 1 {
+			return errors.Errorf("--partitions must be at least 1, got %d", planPartitionsFlagVal)
+		}
+		partition := testplugin.Partition{Total: planPartitionsFlagVal}
+		if err := configurePartition(cmd, &partition, &param); err != nil {
+			return err
+		}
+		plan, err := testplugin.PlanTests(projectDirFlagVal, args, partition, param)
+		if err != nil {
+			return err
+		}
+		return plan.Write(cmd.OutOrStdout(), planFormatFlagVal)
+	},
+}
+
+func init() {
+	planCmd.Flags().IntVar(&planPartitionsFlagVal, "partitions", 1, "total number of partitions")
+	planCmd.Flags().StringVar(&planFormatFlagVal, "format", testplugin.PlanFormatText, `output format: "text" or "json" (the JSON output can be provided to the --plan flag of the 'run' command)`)
+	addPartitionStrategyFlags(planCmd)
+	RootCmd.AddCommand(planCmd)
+}
@@ -44,6 +44,26 @@ var (
 						"path to JUnit XML output (only used if 'test' task is run)",
 						godellauncher.StringFlag,
 					),
+					pluginapi.NewVerifyFlag(
+						"json-output",
+						"path to which the results of the run are written in JSON format (only used if 'test' task is run)",
+						godellauncher.StringFlag,
+					),
+					pluginapi.NewVerifyFlag(
+						"coverage-output",
+						"directory to which the merged coverage profile of all of the tested packages is written (only used if 'test' task is run)",
+						godellauncher.StringFlag,
+					),
+					pluginapi.NewVerifyFlag(
+						"coverage-format",
+						`comma-separated formats of the coverage reports written to the coverage output directory: "cobertura", "lcov" and/or "html" (only used if 'test' task is run)`,
+						godellauncher.StringFlag,
+					),
+					pluginapi.NewVerifyFlag(
+						"coverage-base",
+						"git revision relative to which the coverage of the changed lines is reported (only used if 'test' task is run)",
+						godellauncher.StringFlag,
+					),
 					pluginapi.NewVerifyFlag(
 						"tags",
 						"specify tags that should be used for tests (only used if 'test' task is run)",
@@ -51,7 +71,77 @@ var (
 					),
 					pluginapi.NewVerifyFlag(
 						"partition",
-						"partition packages for parallel testing (format: X,N where X is 0-indexed partition and N is total partitions)",
+						`partition packages for parallel testing (format: X,N where X is 0-indexed partition and N is total partitions, or "auto" to detect from CI environment variables)`,
+						godellauncher.StringFlag,
+					),
+					pluginapi.NewVerifyFlag(
+						"partition-strategy",
+						`strategy used to assign packages to partitions: "contiguous" or "hash" (only used if 'test' task is run)`,
+						godellauncher.StringFlag,
+					),
+					pluginapi.NewVerifyFlag(
+						"partition-timings",
+						"comma-separated JUnit XML reports or timings files used to balance partitions by expected duration (only used if 'test' task is run)",
+						godellauncher.StringFlag,
+					),
+					pluginapi.NewVerifyFlag(
+						"split-package-threshold",
+						"number of top-level tests above which the tests of a package are split across partitions (only used if 'test' task is run)",
+						godellauncher.StringFlag,
+					),
+					pluginapi.NewVerifyFlag(
+						"queue-dir",
+						"directory shared by multiple nodes from which every node claims packages to test one at a time (only used if 'test' task is run)",
+						godellauncher.StringFlag,
+					),
+					pluginapi.NewVerifyFlag(
+						"queue-id",
+						"ID of the run that uses the queue directory, which must be unique for every run (only used if 'test' task is run)",
+						godellauncher.StringFlag,
+					),
+					pluginapi.NewVerifyFlag(
+						"timings-output",
+						"path to which the durations of testing the packages are written (only used if 'test' task is run)",
+						godellauncher.StringFlag,
+					),
+					pluginapi.NewVerifyFlag(
+						"plan",
+						"test plan file that determines the packages of every partition (only used if 'test' task is run)",
+						godellauncher.StringFlag,
+					),
+					pluginapi.NewVerifyFlag(
+						"jobs",
+						"number of packages to test concurrently, each in its own 'go test' process (only used if 'test' task is run)",
+						godellauncher.StringFlag,
+					),
+					pluginapi.NewVerifyFlag(
+						"console-output",
+						`output of the tests printed to the console: "failures" or "verbose" (only used if 'test' task is run)`,
+						godellauncher.StringFlag,
+					),
+					pluginapi.NewVerifyFlag(
+						"progress",
+						"report the progress of the run (only used if 'test' task is run)",
+						godellauncher.BoolFlag,
+					),
+					pluginapi.NewVerifyFlag(
+						"progress-interval",
+						"interval at which progress lines are printed if the output is not a terminal (only used if 'test' task is run)",
+						godellauncher.StringFlag,
+					),
+					pluginapi.NewVerifyFlag(
+						"inactivity-timeout",
+						"duration without any test output after which the running tests are reported as hung (only used if 'test' task is run)",
+						godellauncher.StringFlag,
+					),
+					pluginapi.NewVerifyFlag(
+						"artifacts-dir",
+						"directory to which the goroutine dumps of hung packages are written (only used if 'test' task is run)",
+						godellauncher.StringFlag,
+					),
+					pluginapi.NewVerifyFlag(
+						"retries",
+						"number of times to re-run failed tests (only used if 'test' task is run)",
 						godellauncher.StringFlag,
 					),
 				),
@@ -63,6 +153,21 @@ var (
 			"Print the test packages that match the provided test tags",
 			pluginapi.TaskInfoCommand("tags"),
 		),
+		pluginapi.PluginInfoTaskInfo(
+			"test-plan",
+			"Print the test packages that match the provided test tags assigned to every partition",
+			pluginapi.TaskInfoCommand("plan"),
+		),
+		pluginapi.PluginInfoTaskInfo(
+			"test-coverage-merge",
+			"Merge coverage profiles (such as the profiles written by different partitions) into a single profile",
+			pluginapi.TaskInfoCommand("coverage-merge"),
+		),
+		pluginapi.PluginInfoTaskInfo(
+			"test-junit-merge",
+			"Merge JUnit reports (such as the reports written by different partitions) into a single report",
+			pluginapi.TaskInfoCommand("junit-merge"),
+		),
 		pluginapi.PluginInfoUpgradeConfigTaskInfo(
 			pluginapi.UpgradeConfigTaskInfoCommand("upgrade-config"),
 			pluginapi.LegacyConfigFile("test.yml"),
@@ -15,6 +15,8 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/palantir/godel/v2/framework/pluginapi"
 	"github.com/spf13/cobra"
 )
@@ -22,12 +24,39 @@ import (
 var (
 	DebugFlagVal bool
 
-	projectDirFlagVal      string
-	godelConfigFileFlagVal string
-	testConfigFileFlagVal  string
-	junitOutputFlagVal     string
-	tagsFlagVal            []string
-	partitionFlagVal       string
+	projectDirFlagVal            string
+	godelConfigFileFlagVal       string
+	testConfigFileFlagVal        string
+	junitOutputFlagVal           string
+	junitMergeOutputFlagVal      string
+	jsonOutputFlagVal            string
+	coverageOutputFlagVal        string
+	coverageFormatsFlagVal       []string
+	coverageBaseFlagVal          string
+	coverageMergeOutputFlagVal   string
+	coverageMergeFormatsFlagVal  []string
+	tagsFlagVal                  []string
+	partitionFlagVal             string
+	retriesFlagVal               int
+	partitionTimingsFlagVal      []string
+	partitionStrategyFlagVal     string
+	timingsOutputFlagVal         string
+	planFlagVal                  string
+	planPartitionsFlagVal        int
+	planFormatFlagVal            string
+	splitPackageThresholdFlagVal int
+	queueDirFlagVal              string
+	queueIDFlagVal               string
+	jobsFlagVal                  int
+	inactivityTimeoutFlagVal     time.Duration
+	artifactsDirFlagVal          string
+	failFastFlagVal              bool
+	consoleOutputFlagVal         string
+	colorFlagVal                 string
+	progressFlagVal              bool
+	progressIntervalFlagVal      time.Duration
+	noSummaryFlagVal             bool
+	summarySlowestFlagVal        int
 )
 
 var RootCmd = &cobra.Command{
@@ -33,21 +33,159 @@ var runCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		if cmd.Flags().Changed(retriesFlagName) {
+			param.Retries = retriesFlagVal
+		}
+		if cmd.Flags().Changed(jobsFlagName) {
+			param.Jobs = jobsFlagVal
+		}
+		if cmd.Flags().Changed(consoleOutputFlagName) {
+			param.ConsoleOutput = testplugin.ConsoleOutput(consoleOutputFlagVal)
+		}
+		if cmd.Flags().Changed(progressFlagName) {
+			param.Progress = progressFlagVal
+		}
+		if cmd.Flags().Changed(progressIntervalFlagName) {
+			param.ProgressInterval = progressIntervalFlagVal
+		}
+		if cmd.Flags().Changed(inactivityTimeoutFlagName) {
+			param.InactivityTimeout = inactivityTimeoutFlagVal
+		}
+		if noSummaryFlagVal {
+			param.Summary.Disabled = true
+		}
+		if cmd.Flags().Changed(summarySlowestFlagName) {
+			param.Summary.Slowest = summarySlowestFlagVal
+		}
+		param.Color = testplugin.ColorMode(colorFlagVal)
+		param.ArtifactsDir = artifactsDirFlagVal
+		param.FailFast = failFastFlagVal
+		param.TimingsOutput = timingsOutputFlagVal
+		param.JSONOutput = jsonOutputFlagVal
+		param.CoverageOutput = coverageOutputFlagVal
+		if param.CoverageFormats, err = parseCoverageFormats(coverageFormatsFlagVal); err != nil {
+			return err
+		}
+		param.CoverageBase = coverageBaseFlagVal
+		param.QueueDir = queueDirFlagVal
+		param.QueueID = queueIDFlagVal
 		partition, err := testplugin.ParsePartition(partitionFlagVal)
 		if err != nil {
 			return err
 		}
+		if param.QueueDir != "" {
+			if cmd.Flags().Changed(partitionFlagName) || planFlagVal != "" {
+				return errors.Errorf("--partition and --plan cannot be specified if --queue-dir is specified")
+			}
+			// a partition detected from the environment is ignored because the nodes share the queue instead
+			partition = nil
+			if param.QueueID == "" {
+				if param.QueueID = testplugin.QueueIDFromEnv(); param.QueueID == "" {
+					return errors.Errorf("--queue-id must be specified if --queue-dir is specified and no queue ID is set by the %s or CI environment variables", testplugin.QueueIDEnvVar)
+				}
+			}
+		} else if param.QueueID != "" {
+			return errors.Errorf("--queue-id can only be specified if --queue-dir is specified")
+		}
+		if partition == nil {
+			if len(partitionTimingsFlagVal) > 0 || planFlagVal != "" {
+				return errors.Errorf("--partition-timings and --plan can only be specified if a partition is specified (using --partition or environment variables)")
+			}
+		} else if err := configurePartition(cmd, partition, &param); err != nil {
+			return err
+		}
+		if planFlagVal != "" {
+			if len(tagsFlagVal) > 0 {
+				return errors.Errorf("--tags cannot be specified if --plan is specified")
+			}
+			plan, err := testplugin.ReadTestPlan(planFlagVal)
+			if err != nil {
+				return err
+			}
+			partition.Plan = plan
+		}
 		return testplugin.RunTestCmd(projectDirFlagVal, args, tagsFlagVal, junitOutputFlagVal, partition, param, cmd.OutOrStdout())
 	},
 }
 
+const (
+	partitionFlagName             = "partition"
+	retriesFlagName               = "retries"
+	jobsFlagName                  = "jobs"
+	inactivityTimeoutFlagName     = "inactivity-timeout"
+	consoleOutputFlagName         = "console-output"
+	progressFlagName              = "progress"
+	progressIntervalFlagName      = "progress-interval"
+	partitionStrategyFlagName     = "partition-strategy"
+	partitionTimingsFlagName      = "partition-timings"
+	splitPackageThresholdFlagName = "split-package-threshold"
+	summarySlowestFlagName        = "summary-slowest"
+)
+
 func init() {
 	runCmd.Flags().StringVar(&junitOutputFlagVal, "junit-output", "", "file to which JUnit output is written")
+	runCmd.Flags().StringVar(&jsonOutputFlagVal, "json-output", "", "file to which the results of the run are written in JSON format")
+	runCmd.Flags().StringVar(&coverageOutputFlagVal, "coverage-output", "", "directory to which the merged coverage profile of all of the tested packages is written (coverage is collected for all of the packages in the module)")
+	runCmd.Flags().StringSliceVar(&coverageFormatsFlagVal, "coverage-format", nil, `formats of the coverage reports written to the coverage output directory: "cobertura", "lcov" and/or "html"`)
+	runCmd.Flags().StringVar(&coverageBaseFlagVal, "coverage-base", "", "git revision relative to which the coverage of the changed lines is reported (coverage is collected for all of the packages in the module; not reported for partitioned runs)")
 	runCmd.Flags().StringSliceVar(&tagsFlagVal, "tags", nil, "run tests that are part of the provided tags")
-	runCmd.Flags().StringVar(&partitionFlagVal, "partition", "", "partition packages for parallel testing (format: X,N where X is 0-indexed partition and N is total partitions)")
+	runCmd.Flags().StringVar(&partitionFlagVal, partitionFlagName, "", `partition packages for parallel testing (format: X,N where X is 0-indexed partition and N is total partitions, or "auto" to require detection from CI environment variables; if unspecified, detected from CI environment variables if they are set)`)
+	addPartitionStrategyFlags(runCmd)
+	runCmd.Flags().StringVar(&planFlagVal, "plan", "", "test plan file (written by the 'plan' command or an external scheduler) that determines the packages of every partition")
+	runCmd.Flags().StringVar(&queueDirFlagVal, "queue-dir", "", "directory shared by multiple nodes from which every node claims packages to test one at a time")
+	runCmd.Flags().StringVar(&queueIDFlagVal, "queue-id", "", "ID of the run that uses the queue directory, which must be the same for all of its nodes and unique for every run (if unspecified, detected from CI environment variables; a queue directory used by a previous run is reset)")
+	runCmd.Flags().StringVar(&timingsOutputFlagVal, "timings-output", "", "file to which the durations of testing the packages are written (can be provided to --partition-timings)")
+	runCmd.Flags().IntVar(&retriesFlagVal, retriesFlagName, 0, "number of times to re-run failed tests (overrides the value in the configuration file)")
+	runCmd.Flags().IntVar(&jobsFlagVal, jobsFlagName, 0, "number of packages to test concurrently, each in its own 'go test' process (overrides the value in the configuration file; if 0, all packages are tested by a single process)")
+	runCmd.Flags().StringVar(&consoleOutputFlagVal, consoleOutputFlagName, "", `output of the tests printed to the console: "failures" (only the output of failing tests) or "verbose" (the output of all tests); does not affect the JUnit output (overrides the value in the configuration file; if unspecified, "verbose" if -v is provided)`)
+	runCmd.Flags().StringVar(&colorFlagVal, "color", string(testplugin.ColorAuto), `whether the console output is colored: "auto" (if the output is a terminal, unless the NO_COLOR environment variable is set or the CLICOLOR_FORCE environment variable forces colors), "always" or "never"`)
+	runCmd.Flags().BoolVar(&progressFlagVal, progressFlagName, false, "report the progress of the run: a progress line at the bottom of the output if it is a terminal, or periodic progress lines otherwise (overrides the value in the configuration file)")
+	runCmd.Flags().DurationVar(&progressIntervalFlagVal, progressIntervalFlagName, 0, "interval at which progress lines are printed if the output is not a terminal (overrides the value in the configuration file; if unspecified, 1m)")
+	runCmd.Flags().DurationVar(&inactivityTimeoutFlagVal, inactivityTimeoutFlagName, 0, "duration without any test output after which goroutine dumps of the test processes are captured and the running tests are reported as hung (overrides the value in the configuration file)")
+	runCmd.Flags().BoolVar(&noSummaryFlagVal, "no-summary", false, "do not print the summary of the run once all of the packages have been tested")
+	runCmd.Flags().IntVar(&summarySlowestFlagVal, summarySlowestFlagName, 0, "number of slowest packages and tests listed in the summary of the run (overrides the value in the configuration file)")
+	runCmd.Flags().BoolVar(&failFastFlagVal, "fail-fast", false, "stop testing as soon as any package fails and report the packages that were not tested (failed tests are not retried)")
+	runCmd.Flags().StringVar(&artifactsDirFlagVal, "artifacts-dir", "", "directory to which the goroutine dumps of hung packages are written (if unspecified, a temporary directory is used)")
 	RootCmd.AddCommand(runCmd)
 }
 
+// addPartitionStrategyFlags adds the flags that configure how packages are assigned to partitions to the provided
+// command.
+func addPartitionStrategyFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&partitionStrategyFlagVal, partitionStrategyFlagName, "", `strategy used to assign packages to partitions: "contiguous" or "hash" (overrides the value in the configuration file)`)
+	cmd.Flags().StringSliceVar(&partitionTimingsFlagVal, partitionTimingsFlagName, nil, "JUnit XML reports or timings files from a previous run used to balance partitions by expected duration")
+	cmd.Flags().IntVar(&splitPackageThresholdFlagVal, splitPackageThresholdFlagName, 0, "number of top-level tests above which the tests of a package are split across partitions (overrides the value in the configuration file)")
+}
+
+// configurePartition configures the strategy and timings of the provided partition based on the flags added by
+// addPartitionStrategyFlags and the provided param. The partition strategy and split package threshold of the param
+// are updated if they are overridden by a flag.
+func configurePartition(cmd *cobra.Command, partition *testplugin.Partition, param *testplugin.TestParam) error {
+	if cmd.Flags().Changed(partitionStrategyFlagName) {
+		param.PartitionStrategy = testplugin.PartitionStrategy(partitionStrategyFlagVal)
+	}
+	if cmd.Flags().Changed(splitPackageThresholdFlagName) {
+		param.SplitPackageThreshold = splitPackageThresholdFlagVal
+	}
+	strategy, err := testplugin.ParsePartitionStrategy(string(param.PartitionStrategy))
+	if err != nil {
+		return err
+	}
+	partition.Strategy = strategy
+	if len(partitionTimingsFlagVal) == 0 {
+		return nil
+	}
+	if strategy == testplugin.PartitionStrategyHash {
+		return errors.Errorf("--%s cannot be used with the %q partition strategy", partitionTimingsFlagName, testplugin.PartitionStrategyHash)
+	}
+	timings, err := testplugin.ReadPartitionTimings(projectDirFlagVal, partitionTimingsFlagVal)
+	if err != nil {
+		return err
+	}
+	partition.Timings = timings
+	return nil
+}
+
 func testParamFromFlags(testConfigFile, godelConfigFile string) (testplugin.TestParam, error) {
 	var testCfg config.Test
 	if testConfigFile != "" {
@@ -0,0 +1,179 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// changedCoverage is the coverage of the executable lines (the lines that contain statements) that changed relative
+// to a git revision.
+type changedCoverage struct {
+	// base is the git revision relative to which the lines changed.
+	base                string
+	lines, coveredLines int
+	// uncovered contains the numbers of the changed executable lines that were not covered in ascending order keyed by
+	// the path of their file relative to the project directory.
+	uncovered map[string][]int
+}
+
+// newChangedCoverage returns the coverage in the provided report of the lines of the files in the provided project
+// directory that changed relative to the provided git revision. Changed lines that are not executable (for example,
+// comments) and files that are not in the report (for example, test files) are ignored.
+func newChangedCoverage(projectDir, base string, report coverageReport) (changedCoverage, error) {
+	changed, err := changedLines(projectDir, base)
+	if err != nil {
+		return changedCoverage{}, err
+	}
+	coverage := changedCoverage{
+		base:      base,
+		uncovered: make(map[string][]int),
+	}
+	for _, pkg := range report.Packages {
+		for _, file := range pkg.Files {
+			for _, line := range changed[file.Path] {
+				count, ok := file.Hits[line]
+				if !ok {
+					continue
+				}
+				coverage.lines++
+				if count > 0 {
+					coverage.coveredLines++
+				} else {
+					coverage.uncovered[file.Path] = append(coverage.uncovered[file.Path], line)
+				}
+			}
+		}
+	}
+	return coverage, nil
+}
+
+// printChangedCoverage prints the provided coverage of changed lines followed by the uncovered changed lines of every
+// file.
+func printChangedCoverage(w io.Writer, coverage changedCoverage) {
+	if coverage.lines == 0 {
+		_, _ = fmt.Fprintf(w, "Changed lines coverage: no executable lines changed relative to %s\n", coverage.base)
+		return
+	}
+	_, _ = fmt.Fprintf(w, "Changed lines coverage: %s of the executable lines changed relative to %s (%d/%d)\n", formatCoveragePercent(coverage.coveredLines, coverage.lines), coverage.base, coverage.coveredLines, coverage.lines)
+	if len(coverage.uncovered) == 0 {
+		return
+	}
+	outputParts := []string{"Uncovered changed lines:"}
+	for _, file := range slices.Sorted(maps.Keys(coverage.uncovered)) {
+		outputParts = append(outputParts, file+": "+formatLineRanges(coverage.uncovered[file]))
+	}
+	_, _ = fmt.Fprintln(w, strings.Join(outputParts, "\n\t"))
+}
+
+// formatLineRanges formats the provided line numbers (in ascending order) as comma-separated ranges of consecutive
+// lines: for example, "3-5, 9".
+func formatLineRanges(lines []int) string {
+	var ranges []string
+	for i := 0; i < len(lines); {
+		j := i
+		for j+1 < len(lines) && lines[j+1] == lines[j]+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, strconv.Itoa(lines[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", lines[i], lines[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(ranges, ", ")
+}
+
+// changedLines returns the numbers of the lines of the files in the provided project directory that were added or
+// modified relative to the merge base of the provided git revision and HEAD keyed by the path of their file relative
+// to the project directory. The changes include the changes in the working tree that have not been committed, but not
+// files that are not tracked by git.
+func changedLines(projectDir, base string) (map[string][]int, error) {
+	mergeBase, err := runGit(projectDir, "merge-base", base, "HEAD")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to determine the merge base of %s and HEAD", base)
+	}
+	// the prefixes are specified so that the paths of the files are parsed correctly regardless of the "diff.noprefix"
+	// and "diff.mnemonicPrefix" configuration of the repository
+	diff, err := runGit(projectDir, "diff", "--no-color", "--no-ext-diff", "--no-renames", "--unified=0", "--src-prefix=a/", "--dst-prefix=b/", "--relative", strings.TrimSpace(mergeBase), "--", ".")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to determine the lines that changed relative to %s", base)
+	}
+	return parseChangedLines(diff)
+}
+
+var hunkHeaderRegexp = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// parseChangedLines parses the added and modified lines from the provided output of "git diff --unified=0".
+func parseChangedLines(diff string) (map[string][]int, error) {
+	changed := make(map[string][]int)
+	file := ""
+	scanner := bufio.NewScanner(strings.NewReader(diff))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if newFile, ok := strings.CutPrefix(line, "+++ "); ok {
+			// deleted files have no new lines ("+++ /dev/null")
+			file = strings.TrimPrefix(newFile, "b/")
+			continue
+		}
+		match := hunkHeaderRegexp.FindStringSubmatch(line)
+		if match == nil || file == "" {
+			continue
+		}
+		start, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid hunk header %q", line)
+		}
+		count := 1
+		if match[2] != "" {
+			if count, err = strconv.Atoi(match[2]); err != nil {
+				return nil, errors.Wrapf(err, "invalid hunk header %q", line)
+			}
+		}
+		for i := range count {
+			changed[file] = append(changed[file], start+i)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "failed to read diff")
+	}
+	return changed, nil
+}
+
+// runGit runs git with the provided arguments in the provided directory and returns its output.
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", errors.Wrapf(err, "%s failed: %s", strings.Join(cmd.Args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return string(output), nil
+}
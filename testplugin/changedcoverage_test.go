@@ -0,0 +1,125 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTestCmdCoverageBase(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeCoverageTestModule(t, tmpDir)
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "base"},
+	} {
+		_, err := runGit(tmpDir, args...)
+		require.NoError(t, err)
+	}
+
+	// the change to Add is covered by the tests of package a, but the new Mul function is not covered
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "a", "a.go"), []byte("package a\n\nfunc Add(x, y int) int {\n\tz := x + y\n\treturn z\n}\n\nfunc Sub(x, y int) int {\n\treturn x - y\n}\n\n// Mul returns the product of x and y.\nfunc Mul(x, y int) int {\n\tz := x * y\n\treturn z\n}\n"), 0644))
+
+	var stdout bytes.Buffer
+	err := RunTestCmd(tmpDir, nil, nil, "", nil, TestParam{CoverageBase: "HEAD"}, &stdout)
+	require.NoError(t, err, stdout.String())
+	assert.Contains(t, stdout.String(), "Changed lines coverage: 50.0% of the executable lines changed relative to HEAD (2/4)\n"+
+		"Uncovered changed lines:\n"+
+		"\ta/a.go: 14-15\n")
+
+	stdout.Reset()
+	err = RunTestCmd(tmpDir, nil, nil, "", nil, TestParam{CoverageBase: "HEAD", Coverage: CoverageParam{ChangedMin: 60}}, &stdout)
+	assert.EqualError(t, err, "1 coverage minimum(s) not met:\n\tchanged lines: 50.00% of lines (2/4), minimum 60%, 10.00% short")
+
+	// the changed lines are determined regardless of the prefixes that the configuration of the repository specifies
+	// for the paths in the output of "git diff"
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "b", "b.go"), []byte("package b\n\nimport \"testmod/a\"\n\nfunc Diff(x, y int) int {\n\tz := a.Sub(x, y)\n\treturn z\n}\n"), 0644))
+	for _, config := range []string{"diff.noprefix", "diff.mnemonicPrefix"} {
+		_, err := runGit(tmpDir, "config", config, "true")
+		require.NoError(t, err)
+		lines, err := changedLines(tmpDir, "HEAD")
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]int{"a/a.go": {4, 5, 11, 12, 13, 14, 15, 16}, "b/b.go": {6, 7}}, lines, config)
+		_, err = runGit(tmpDir, "config", "--unset", config)
+		require.NoError(t, err)
+	}
+}
+
+func TestParseChangedLines(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		diff string
+		want map[string][]int
+	}{
+		{
+			name: "added and modified lines",
+			diff: "diff --git a/a/a.go b/a/a.go\n" +
+				"index e9af549..541f445 100644\n" +
+				"--- a/a/a.go\n" +
+				"+++ b/a/a.go\n" +
+				"@@ -4 +4,2 @@ func Add(x, y int) int {\n" +
+				"-\treturn x + y\n" +
+				"+\tz := x + y\n" +
+				"+\treturn z\n" +
+				"@@ -12,0 +14 @@ func Sub(x, y int) int {\n" +
+				"+// comment\n" +
+				"diff --git a/b/b.go b/b/b.go\n" +
+				"--- a/b/b.go\n" +
+				"+++ b/b/b.go\n" +
+				"@@ -3,2 +2,0 @@\n" +
+				"-import \"fmt\"\n" +
+				"-\n",
+			want: map[string][]int{
+				"a/a.go": {4, 5, 14},
+			},
+		},
+		{
+			name: "deleted file",
+			diff: "diff --git a/c.go b/c.go\n" +
+				"deleted file mode 100644\n" +
+				"--- a/c.go\n" +
+				"+++ /dev/null\n" +
+				"@@ -1,3 +0,0 @@\n" +
+				"-package c\n",
+			want: map[string][]int{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseChangedLines(tc.diff)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestFormatLineRanges(t *testing.T) {
+	for i, tc := range []struct {
+		lines []int
+		want  string
+	}{
+		{lines: []int{3}, want: "3"},
+		{lines: []int{3, 4, 5, 9}, want: "3-5, 9"},
+		{lines: []int{1, 3, 4}, want: "1, 3-4"},
+	} {
+		assert.Equal(t, tc.want, formatLineRanges(tc.lines), "Case %d", i)
+	}
+}
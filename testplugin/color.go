@@ -0,0 +1,89 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// ColorMode determines whether the console output is colored using ANSI escape sequences. Output written to files
+// (such as the JUnit output) is never colored.
+type ColorMode string
+
+const (
+	// ColorAuto colors the console output if it is a terminal. The NO_COLOR and CLICOLOR_FORCE environment variables
+	// disable and force colors respectively.
+	ColorAuto ColorMode = "auto"
+	// ColorAlways always colors the console output.
+	ColorAlways ColorMode = "always"
+	// ColorNever never colors the console output.
+	ColorNever ColorMode = "never"
+)
+
+// ParseColorMode parses the provided color mode. An empty input is equivalent to ColorAuto.
+func ParseColorMode(s string) (ColorMode, error) {
+	switch mode := ColorMode(s); mode {
+	case "":
+		return ColorAuto, nil
+	case ColorAuto, ColorAlways, ColorNever:
+		return mode, nil
+	default:
+		return "", errors.Errorf("invalid color mode %q: must be one of %q, %q or %q", s, ColorAuto, ColorAlways, ColorNever)
+	}
+}
+
+// useColor returns true if output written to "out" should be colored in the provided mode. In ColorAuto mode, a
+// non-empty NO_COLOR environment variable disables colors (see https://no-color.org) and a CLICOLOR_FORCE environment
+// variable with a value other than "0" forces them; otherwise, the output is colored if it is a terminal.
+func useColor(out io.Writer, mode ColorMode) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return true
+	}
+	return isTerminal(out)
+}
+
+const (
+	ansiReset  = "\033[0m"
+	ansiBold   = "\033[1m"
+	ansiRed    = "\033[31m"
+	ansiGreen  = "\033[32m"
+	ansiYellow = "\033[33m"
+	ansiCyan   = "\033[36m"
+)
+
+// colors colors text using ANSI escape sequences. If disabled, text is returned unmodified.
+type colors struct {
+	enabled bool
+}
+
+// paint returns the provided text wrapped in the provided escape sequence (for example, ansiRed).
+func (c colors) paint(code, s string) string {
+	if !c.enabled || code == "" || s == "" {
+		return s
+	}
+	return code + s + ansiReset
+}
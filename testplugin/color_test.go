@@ -0,0 +1,71 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseColor(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		mode          ColorMode
+		noColor       string
+		cliColorForce string
+		want          bool
+	}{
+		{name: "auto without a terminal", mode: ColorAuto, want: false},
+		{name: "always", mode: ColorAlways, noColor: "1", want: true},
+		{name: "never", mode: ColorNever, cliColorForce: "1", want: false},
+		{name: "CLICOLOR_FORCE forces colors", mode: ColorAuto, cliColorForce: "1", want: true},
+		{name: "CLICOLOR_FORCE of 0 does not force colors", mode: ColorAuto, cliColorForce: "0", want: false},
+		{name: "NO_COLOR takes precedence over CLICOLOR_FORCE", mode: ColorAuto, noColor: "1", cliColorForce: "1", want: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tc.noColor)
+			t.Setenv("CLICOLOR_FORCE", tc.cliColorForce)
+			assert.Equal(t, tc.want, useColor(&bytes.Buffer{}, tc.mode))
+		})
+	}
+}
+
+func TestConsolePrinterColors(t *testing.T) {
+	var console bytes.Buffer
+	c := newConsolePrinter(&console, false, len("pkg/fail"), colors{enabled: true})
+	for _, ev := range []testEvent{
+		{Action: actionOutput, Package: "pkg/ok", Output: "ok  \tpkg/ok\t(cached)\n"},
+		{Action: actionRun, Package: "pkg/fail", Test: "TestFail"},
+		{Action: actionOutput, Package: "pkg/fail", Test: "TestFail", Output: "    fail_test.go:5: boom\n"},
+		{Action: actionOutput, Package: "pkg/fail", Test: "TestFail", Output: "--- FAIL: TestFail (0.00s)\n"},
+		{Action: actionFail, Package: "pkg/fail", Test: "TestFail"},
+		{Action: actionOutput, Package: "pkg/fail", Output: "FAIL\n"},
+		{Action: actionOutput, Package: "pkg/fail", Output: "FAIL\tpkg/fail\t0.01s\n"},
+		{Action: actionOutput, Package: "pkg/none", Output: "?   \tpkg/none\t[no test files]\n"},
+	} {
+		require.NoError(t, c.printEvent(ev))
+	}
+	assert.Equal(t, ""+
+		"\033[32mok\033[0m  \tpkg/ok  \t\033[36m(cached)\033[0m\n"+
+		"    fail_test.go:5: boom\n"+
+		"--- \033[31m\033[1mFAIL: TestFail\033[0m (0.00s)\n"+
+		"\033[31m\033[1mFAIL\033[0m\n"+
+		"\033[31m\033[1mFAIL\033[0m\tpkg/fail\t0.01s\n"+
+		"\033[33m?\033[0m   \tpkg/none\t[no test files]\n",
+		console.String())
+}
@@ -15,6 +15,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/palantir/godel-test-plugin/testplugin"
 	v0 "github.com/palantir/godel-test-plugin/testplugin/config/internal/v0"
 	"github.com/palantir/pkg/matcher"
@@ -28,7 +30,43 @@ func (cfg *Test) ToParam() testplugin.TestParam {
 		m[k] = v.Matcher()
 	}
 	return testplugin.TestParam{
-		Tags:    m,
-		Exclude: cfg.Exclude.Matcher(),
+		Tags:                  m,
+		Exclude:               cfg.Exclude.Matcher(),
+		Retries:               cfg.Retries,
+		PartitionStrategy:     testplugin.PartitionStrategy(cfg.PartitionStrategy),
+		Jobs:                  cfg.Jobs,
+		InactivityTimeout:     time.Duration(cfg.InactivityTimeout),
+		SplitPackageThreshold: cfg.SplitPackageThreshold,
+		ConsoleOutput:         testplugin.ConsoleOutput(cfg.ConsoleOutput),
+		Progress:              cfg.Progress,
+		ProgressInterval:      time.Duration(cfg.ProgressInterval),
+		Summary:               cfg.summaryParam(),
+		Coverage:              cfg.coverageParam(),
+	}
+}
+
+func (cfg *Test) coverageParam() testplugin.CoverageParam {
+	param := testplugin.CoverageParam{
+		Min:        cfg.Coverage.Min,
+		Tags:       cfg.Coverage.Tags,
+		ChangedMin: cfg.Coverage.ChangedMin,
+	}
+	for _, pkgCfg := range cfg.Coverage.Packages {
+		param.Packages = append(param.Packages, testplugin.PackageCoverageMin{
+			Matcher: pkgCfg.Matcher(),
+			Min:     pkgCfg.Min,
+		})
+	}
+	return param
+}
+
+func (cfg *Test) summaryParam() testplugin.SummaryParam {
+	slowest := testplugin.DefaultSummarySlowest
+	if cfg.Summary.Slowest != nil {
+		slowest = *cfg.Summary.Slowest
+	}
+	return testplugin.SummaryParam{
+		Disabled: cfg.Summary.Disabled,
+		Slowest:  slowest,
 	}
 }
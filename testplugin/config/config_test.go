@@ -17,8 +17,10 @@ package config_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/palantir/godel-test-plugin/testplugin/config"
+	v0 "github.com/palantir/godel-test-plugin/testplugin/config/internal/v0"
 	"github.com/palantir/pkg/matcher"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -52,6 +54,25 @@ exclude:
   paths:
     - "vendor"
     - "generated_src"
+retries: 2
+partition-strategy: hash
+split-package-threshold: 200
+jobs: 4
+inactivity-timeout: 10m
+console-output: failures
+progress: true
+progress-interval: 30s
+summary:
+  slowest: 10
+coverage:
+  min: 70
+  packages:
+    - paths:
+        - "pkg/core"
+      min: 90
+  tags:
+    integration: 50
+  changed-min: 80
 `,
 			want: config.Test{
 				Tags: map[string]matcher.NamesPathsWithExcludeCfg{
@@ -70,6 +91,32 @@ exclude:
 					Names: []string{`.*test`, `m?cks`, `gunit`},
 					Paths: []string{`vendor`, `generated_src`},
 				},
+				Retries:               2,
+				PartitionStrategy:     "hash",
+				Jobs:                  4,
+				InactivityTimeout:     v0.Duration(10 * time.Minute),
+				SplitPackageThreshold: 200,
+				ConsoleOutput:         "failures",
+				Progress:              true,
+				ProgressInterval:      v0.Duration(30 * time.Second),
+				Summary: v0.SummaryConfig{
+					Slowest: new(10),
+				},
+				Coverage: v0.CoverageConfig{
+					Min: 70,
+					Packages: []v0.PackageCoverageConfig{
+						{
+							NamesPathsCfg: matcher.NamesPathsCfg{
+								Paths: []string{`pkg/core`},
+							},
+							Min: 90,
+						},
+					},
+					Tags: map[string]float64{
+						"integration": 50,
+					},
+					ChangedMin: 80,
+				},
 			},
 			wantParamKeys: map[string]struct{}{
 				"integration": {},
@@ -172,6 +219,31 @@ tags:
 `,
 			wantError: `"all" is a reserved name that cannot be used as a tag name`,
 		},
+		{
+			name: "coverage minimums must be percentages",
+			yml: `
+coverage:
+  packages:
+    - names:
+        - "core"
+      min: 120
+`,
+			wantError: "coverage minimum of packages must be between 0 and 100, got 120",
+		},
+		{
+			name: "coverage minimums of tags must refer to defined tags",
+			yml: `
+tags:
+  integration:
+    names:
+      - "integration_tests"
+coverage:
+  tags:
+    Integration: 50
+    unit: 50
+`,
+			wantError: `coverage minimum specified for tag "unit", which is not defined`,
+		},
 	} {
 		var got config.Test
 		err := yaml.Unmarshal([]byte(tc.yml), &got)
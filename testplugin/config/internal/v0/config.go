@@ -15,6 +15,8 @@
 package v0
 
 import (
+	"time"
+
 	"github.com/palantir/pkg/matcher"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
@@ -28,6 +30,103 @@ type Config struct {
 
 	// Exclude specifies the files that should be excluded from tests.
 	Exclude matcher.NamesPathsCfg `yaml:"exclude,omitempty"`
+
+	// Retries is the number of times that the tests that failed are re-run. A test that passes on a retry is reported
+	// as flaky rather than failed.
+	Retries int `yaml:"retries,omitempty"`
+
+	// PartitionStrategy is the strategy used to assign packages to partitions when tests are partitioned: either
+	// "contiguous" (the default) or "hash".
+	PartitionStrategy string `yaml:"partition-strategy,omitempty"`
+
+	// Jobs is the number of "go test" processes that are run concurrently. If greater than 0, every package is tested
+	// by its own "go test" process. If 0 (the default), all of the packages are tested by a single process.
+	Jobs int `yaml:"jobs,omitempty"`
+
+	// InactivityTimeout is the duration without any output from "go test" after which the tests are considered to be
+	// hung (for example, "10m"). If 0 (the default), tests are never considered to be hung.
+	InactivityTimeout Duration `yaml:"inactivity-timeout,omitempty"`
+
+	// SplitPackageThreshold is the number of top-level tests above which the tests of a package are split across
+	// partitions. If 0 (the default), packages are not split.
+	SplitPackageThreshold int `yaml:"split-package-threshold,omitempty"`
+
+	// ConsoleOutput determines the output of the tests that is printed to the console: either "failures" (only the
+	// output of failing tests) or "verbose" (the output of all tests). If unspecified, the output of all tests is only
+	// printed if the "-v" flag is provided to "go test". The JUnit output is not affected.
+	ConsoleOutput string `yaml:"console-output,omitempty"`
+
+	// Progress reports the progress of the run while the packages are tested: if the output is a terminal, a progress
+	// line is kept at the bottom of the output; otherwise, a progress line is printed every ProgressInterval.
+	Progress bool `yaml:"progress,omitempty"`
+
+	// ProgressInterval is the interval at which progress lines are printed if the output is not a terminal (for
+	// example, "30s"). If unspecified, progress lines are printed every minute.
+	ProgressInterval Duration `yaml:"progress-interval,omitempty"`
+
+	// Summary configures the summary of the run that is printed once all of the packages have been tested.
+	Summary SummaryConfig `yaml:"summary,omitempty"`
+
+	// Coverage configures the minimum coverage that the tests must achieve.
+	Coverage CoverageConfig `yaml:"coverage,omitempty"`
+}
+
+// CoverageConfig configures the minimum percentages of statements that the tests must cover. If any minimums are
+// specified, coverage is collected for all of the packages in the module and the "test" task fails if any minimum is
+// not met.
+type CoverageConfig struct {
+	// Min is the minimum percentage of the statements of all of the packages in the module that must be covered.
+	Min float64 `yaml:"min,omitempty"`
+
+	// Packages are the minimum percentages of the statements of the packages that match the provided matchers that
+	// must be covered. The minimum of every package is the highest minimum of the matchers that match it.
+	Packages []PackageCoverageConfig `yaml:"packages,omitempty"`
+
+	// Tags contains the minimum percentage of the statements of the module that must be covered by the tests of the
+	// packages that are part of a tag keyed by tag. The minimums are only checked for the tags that were tested.
+	Tags map[string]float64 `yaml:"tags,omitempty"`
+
+	// ChangedMin is the minimum percentage of the executable lines that changed relative to the coverage base (the git
+	// revision provided using the "--coverage-base" flag) that must be covered. Ignored if no coverage base is provided.
+	ChangedMin float64 `yaml:"changed-min,omitempty"`
+}
+
+// PackageCoverageConfig is the minimum coverage of the packages that match a matcher.
+type PackageCoverageConfig struct {
+	matcher.NamesPathsCfg `yaml:",inline"`
+
+	// Min is the minimum percentage of the statements of every package that matches the matcher that must be covered.
+	Min float64 `yaml:"min"`
+}
+
+// SummaryConfig configures the summary of the run.
+type SummaryConfig struct {
+	// Disabled suppresses the summary.
+	Disabled bool `yaml:"disabled,omitempty"`
+
+	// Slowest is the number of slowest packages and tests that are listed in the summary. If unspecified, 5 are
+	// listed. If 0, none are listed.
+	Slowest *int `yaml:"slowest,omitempty"`
+}
+
+// Duration is a time.Duration that is specified as a string in the format accepted by time.ParseDuration.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return errors.Wrapf(err, "invalid duration %q", s)
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).String(), nil
 }
 
 func UpgradeConfig(cfgBytes []byte) ([]byte, error) {
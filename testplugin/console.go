@@ -0,0 +1,278 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ConsoleOutput determines the output of the tests that is printed to the console. It does not affect the JUnit
+// output, which always contains the output of all of the tests.
+type ConsoleOutput string
+
+const (
+	// ConsoleOutputFailures buffers the output of every test and only prints it if the test fails. The package summary
+	// lines are always printed.
+	ConsoleOutputFailures ConsoleOutput = "failures"
+	// ConsoleOutputVerbose prints the output of all of the tests as it is written, which matches the output of
+	// "go test -v".
+	ConsoleOutputVerbose ConsoleOutput = "verbose"
+)
+
+// ParseConsoleOutput parses the provided console output. An empty input is returned unmodified: in that case, the
+// console output is ConsoleOutputVerbose if the "-v" flag is provided to "go test" and ConsoleOutputFailures
+// otherwise.
+func ParseConsoleOutput(s string) (ConsoleOutput, error) {
+	switch output := ConsoleOutput(s); output {
+	case "", ConsoleOutputFailures, ConsoleOutputVerbose:
+		return output, nil
+	default:
+		return "", errors.Errorf("invalid console output %q: must be one of %q or %q", s, ConsoleOutputFailures, ConsoleOutputVerbose)
+	}
+}
+
+// consolePrinter prints the events of a "go test -json" command to the console in the format that "go test" uses for
+// its text output, with the package summary lines ("ok", "FAIL" and "?") aligned. If verbose is false, the output of
+// each test is buffered and is only printed if the test fails, which matches the output of "go test" when it is run
+// without the "-v" flag. If colors are enabled, the package results and the results of the tests are colored.
+type consolePrinter struct {
+	out               io.Writer
+	verbose           bool
+	longestPkgNameLen int
+	colors            colors
+	// pendingTests contains the buffered output of the top-level tests that have not completed. Only used if verbose
+	// is false.
+	pendingTests map[pendingTestKey][]string
+	pendingOrder []pendingTestKey
+}
+
+// pendingTestKey identifies a top-level test: the output of subtests is buffered with the output of their top-level
+// test so that it is printed in the order in which it was written.
+type pendingTestKey struct {
+	pkg  string
+	test string
+}
+
+func newConsolePrinter(out io.Writer, verbose bool, longestPkgNameLen int, colors colors) *consolePrinter {
+	return &consolePrinter{
+		out:               out,
+		verbose:           verbose,
+		longestPkgNameLen: longestPkgNameLen,
+		colors:            colors,
+		pendingTests:      make(map[pendingTestKey][]string),
+	}
+}
+
+func (c *consolePrinter) printEvent(ev testEvent) error {
+	switch {
+	case ev.Action == actionBuildOutput:
+		return c.printRaw(ev.Output)
+	case ev.Test == "" && ev.Action == actionOutput:
+		return c.printPkgOutput(ev)
+	case ev.Test == "" && ev.isTerminal():
+		c.discardPending(ev.Package)
+		return nil
+	case ev.Test == "" || c.verbose:
+		if ev.Action == actionOutput {
+			return c.printRaw(c.colorTestOutput(ev.Output))
+		}
+		return nil
+	}
+
+	key := pendingTestKey{pkg: ev.Package, test: topLevelTestName(ev.Test)}
+	switch {
+	case ev.Action == actionOutput:
+		if strings.HasPrefix(ev.Output, "=== ") && isFrameLine(ev.Output) {
+			// "go test" does not print the start, pause or continuation of tests when it is not verbose
+			return nil
+		}
+		if _, ok := c.pendingTests[key]; !ok {
+			c.pendingOrder = append(c.pendingOrder, key)
+		}
+		c.pendingTests[key] = append(c.pendingTests[key], c.colorTestOutput(ev.Output))
+	case ev.isTerminal() && ev.Test == key.test:
+		output := c.pendingTests[key]
+		c.removePending(key)
+		if ev.Action == actionFail {
+			return c.printRaw(strings.Join(output, ""))
+		}
+	}
+	return nil
+}
+
+// printPkgOutput prints output that is not attributed to any test. Package summary lines are aligned.
+func (c *consolePrinter) printPkgOutput(ev testEvent) error {
+	summaryLine, isSummaryLine := c.alignSummaryLine(ev.Package, ev.Output)
+	if isSummaryLine || strings.TrimSpace(ev.Output) == "FAIL" {
+		// the package has finished running its tests: print the output of any tests that did not complete (for
+		// example, because the test binary panicked or timed out) before the package result
+		if err := c.flushPending(ev.Package); err != nil {
+			return err
+		}
+	}
+	if isSummaryLine {
+		return c.printRaw(summaryLine)
+	}
+	if !c.verbose && (strings.TrimSpace(ev.Output) == "PASS" || strings.HasPrefix(ev.Output, "coverage: ")) {
+		// "go test" only prints the result and coverage lines of passing packages when it is not verbose (the coverage
+		// is also included in the summary line of the package)
+		return nil
+	}
+	switch line, hasNewline := strings.CutSuffix(ev.Output, "\n"); line {
+	case "PASS", "FAIL":
+		line = c.colors.paint(resultColors[line], line)
+		if hasNewline {
+			line += "\n"
+		}
+		return c.printRaw(line)
+	}
+	return c.printRaw(ev.Output)
+}
+
+// resultColors are the colors of the results of packages and tests in the console output.
+var resultColors = map[string]string{
+	"ok":   ansiGreen,
+	"PASS": ansiGreen,
+	"FAIL": ansiRed + ansiBold,
+	"SKIP": ansiYellow,
+	"?":    ansiYellow,
+}
+
+var testResultLineRegexp = regexp.MustCompile(`^(\s*--- )(PASS|FAIL|SKIP)(: .*?)( \(\d+\.\d+s\))?(\n?)$`)
+
+// colorTestOutput colors the provided line of the output of a test if it is the result line of the test (for example,
+// "--- FAIL: TestFoo (0.00s)"): the result and the name of the test are colored based on the result.
+func (c *consolePrinter) colorTestOutput(output string) string {
+	if !c.colors.enabled {
+		return output
+	}
+	match := testResultLineRegexp.FindStringSubmatch(output)
+	if match == nil {
+		return output
+	}
+	return match[1] + c.colors.paint(resultColors[match[2]], match[2]+match[3]) + match[4] + match[5]
+}
+
+func (c *consolePrinter) printRaw(output string) error {
+	_, err := io.WriteString(c.out, output)
+	return err
+}
+
+// flushPending prints the buffered output of all the tests in the provided package that have not completed.
+func (c *consolePrinter) flushPending(pkg string) error {
+	for _, key := range c.pendingKeys(pkg) {
+		output := c.pendingTests[key]
+		c.removePending(key)
+		if err := c.printRaw(strings.Join(output, "")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// discardPending discards the buffered output of all the tests in the provided package.
+func (c *consolePrinter) discardPending(pkg string) {
+	for _, key := range c.pendingKeys(pkg) {
+		c.removePending(key)
+	}
+}
+
+func (c *consolePrinter) pendingKeys(pkg string) []pendingTestKey {
+	var keys []pendingTestKey
+	for _, key := range c.pendingOrder {
+		if key.pkg == pkg {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (c *consolePrinter) removePending(key pendingTestKey) {
+	delete(c.pendingTests, key)
+	for i, curr := range c.pendingOrder {
+		if curr == key {
+			c.pendingOrder = append(c.pendingOrder[:i], c.pendingOrder[i+1:]...)
+			break
+		}
+	}
+}
+
+var setupFailedRegexp = regexp.MustCompile(`(^FAIL\t.+) (\[setup failed\]$)`)
+
+// alignSummaryLine returns the aligned version of the provided output if it is the summary line for the provided
+// package (a line of the form "[ok|FAIL|?]\t[pkgName]\t[time|no test files]", or "\t[pkgName]\t\tcoverage: [...]" for
+// a package without test files when coverage is collected). Returns false if the output is not the summary line for
+// the package.
+func (c *consolePrinter) alignSummaryLine(pkg, output string) (string, bool) {
+	line, hasNewline := strings.CutSuffix(output, "\n")
+	if !strings.HasPrefix(line, "ok") && !strings.HasPrefix(line, "FAIL") && !strings.HasPrefix(line, "?") && !strings.HasPrefix(line, "\t") {
+		return "", false
+	}
+	if setupFailedRegexp.MatchString(line) {
+		// if line matches "setup failed" output, modify output to conform to expected style
+		// (namely, replace space between package name and "[setup failed]" with a tab)
+		line = setupFailedRegexp.ReplaceAllString(line, "$1\t$2")
+	}
+
+	// split into at most 4 parts
+	fields := strings.SplitN(line, "\t", 4)
+
+	// valid summary lines have at least 3 parts: "[ok|FAIL|?]\t[pkgName]\t[time|no test files]"
+	if len(fields) < 3 || strings.TrimSpace(fields[1]) != pkg {
+		return "", false
+	}
+	c.colorSummaryFields(fields)
+	line = alignLine(fields, c.longestPkgNameLen)
+	if hasNewline {
+		line += "\n"
+	}
+	return line, true
+}
+
+// colorSummaryFields colors the result (the first field) of the provided package summary line fields and the
+// "(cached)" marker of cached packages.
+func (c *consolePrinter) colorSummaryFields(fields []string) {
+	result := strings.TrimRight(fields[0], " ")
+	fields[0] = c.colors.paint(resultColors[result], result) + fields[0][len(result):]
+	if rest, ok := strings.CutPrefix(fields[2], "(cached)"); ok {
+		fields[2] = c.colors.paint(ansiCyan, "(cached)") + rest
+	}
+}
+
+// topLevelTestName returns the name of the top-level test of the provided test: for example, "TestFoo" for
+// "TestFoo/bar/baz".
+func topLevelTestName(test string) string {
+	name, _, _ := strings.Cut(test, "/")
+	return name
+}
+
+// alignLine returns a string where the length of the second field (fields[1]) is padded with spaces to make its length
+// equal to the value of maxPkgLen and the fields are joined with tab characters. Assuming that the first field is
+// always the same length, this method ensures that the third field will always be aligned together for any fixed value
+// of maxPkgLen.
+func alignLine(fields []string, maxPkgLen int) string {
+	currPkgName := fields[1]
+	repeat := max(maxPkgLen-len(currPkgName),
+		// this should not occur under normal circumstances. However, it appears that it is possible if tests
+		// create test packages in the directory structure while tests are already running. If such a case is
+		// encountered, having output that isn't aligned optimally is better than crashing, so set repeat to 0.
+		0)
+	fields[1] = currPkgName + strings.Repeat(" ", repeat)
+	return strings.Join(fields, "\t")
+}
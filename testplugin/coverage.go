@@ -0,0 +1,389 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/cover"
+)
+
+// CoverageProfileFile is the name of the merged coverage profile that is written to the coverage output directory.
+const CoverageProfileFile = "coverage.out"
+
+// coverageCollector collects the coverage profiles written by all of the "go test" commands of a run (see
+// addProfileFlag) and merges them into a single profile. Safe for concurrent use.
+type coverageCollector struct {
+	projectDir string
+	// outputDir is the directory to which the merged profile and the coverage reports are written. If empty, the
+	// merged profile is not written.
+	outputDir string
+	formats   []CoverageFormat
+	// param determines the tags for which the coverage of their tests is reported (see writeTagCoverage).
+	param TestParam
+	// profilesDir is the temporary directory to which the profiles of the commands are written.
+	profilesDir string
+	mu          sync.Mutex
+	profiles    []string
+	// profilePkgs contains the import paths of the packages tested by the command that wrote every profile keyed by
+	// profile.
+	profilePkgs map[string][]string
+}
+
+// newCoverageCollector returns a collector that writes the merged coverage profile of a run to the provided output
+// directory (if it is non-empty), along with the provided "go test" arguments updated to collect the coverage of all of
+// the packages in the module in the project directory: unless the arguments already specify "-coverpkg", it is set to
+// all of the packages in the module so that the coverage of a package includes the tests of the other packages that
+// exercise it.
+// The arguments must not specify "-coverprofile", which is set for every command by the collector. Coverage reports in
+// the formats of the provided param are written along with the merged profile, as well as the coverage of the tests of
+// every tag of the param.
+func newCoverageCollector(projectDir, outputDir string, param TestParam, testArgs []string) (*coverageCollector, []string, error) {
+	if hasTestFlag(testArgs, "coverprofile") {
+		return nil, nil, errors.Errorf(`"-coverprofile" cannot be provided to "go test" if a coverage output directory, coverage minimums or a coverage base are specified`)
+	}
+	if !hasTestFlag(testArgs, "coverpkg") {
+		modPath, err := modulePath(projectDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		testArgs = append([]string{"-coverpkg=" + modPath + "/..."}, testArgs...)
+	}
+	if outputDir != "" {
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to create coverage output directory")
+		}
+	}
+	profilesDir, err := os.MkdirTemp("", "godel-test-plugin-coverage-")
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to create temporary directory for coverage profiles")
+	}
+	return &coverageCollector{
+		projectDir:  projectDir,
+		outputDir:   outputDir,
+		formats:     param.CoverageFormats,
+		param:       param,
+		profilesDir: profilesDir,
+		profilePkgs: make(map[string][]string),
+	}, testArgs, nil
+}
+
+// addProfileFlag adds a "-coverprofile" flag to the provided "go test" command (created by goTestCmd) that writes the
+// coverage profile of the command to a new file in the temporary directory of the collector and returns the file.
+// Safe to call on a nil collector, in which case the command is not modified and "" is returned.
+func (c *coverageCollector) addProfileFlag(cmd *exec.Cmd) string {
+	if c == nil {
+		return ""
+	}
+	c.mu.Lock()
+	profile := filepath.Join(c.profilesDir, fmt.Sprintf("%d.out", len(c.profiles)))
+	c.profiles = append(c.profiles, profile)
+	c.mu.Unlock()
+	// the flag is inserted after "go test -json" so that it precedes the packages and any "-args" flag
+	cmd.Args = slices.Insert(cmd.Args, 3, "-coverprofile="+profile)
+	return profile
+}
+
+// recordPkgs records the import paths of the packages tested by the command that writes the provided profile (returned
+// by addProfileFlag). Safe to call on a nil collector, in which case it does nothing.
+func (c *coverageCollector) recordPkgs(profile string, pkgs []string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.profilePkgs[profile] = pkgs
+}
+
+// reportsTags returns true if the coverage of the tests of every tag of the param is collected, which is the case if
+// the param has tags and the merged profile is written or coverage minimums are specified for tags. The profile of
+// every command can then only be attributed to its tags if all of the packages of the command are part of the same
+// tags (see groupPkgsByTags). Safe to call on a nil collector, in which case false is returned.
+func (c *coverageCollector) reportsTags() bool {
+	return c != nil && len(c.param.Tags) > 0 && (c.outputDir != "" || len(c.param.Coverage.Tags) > 0)
+}
+
+// finish merges the coverage profiles of all of the commands, writes the merged profile and the coverage reports to the
+// output directory (if any), prints the coverage of the merged profile and returns it along with the merged profile of
+// every coverage group if the coverage of the tests of every tag is collected (see reportsTags and
+// tagCoverageProfiles). Profiles that were not written (for example, because the packages of a command failed to
+// build) are ignored. Returns nil if no profiles were written.
+func (c *coverageCollector) finish(stdout io.Writer) ([]*cover.Profile, map[string][]*cover.Profile, error) {
+	var files []string
+	for _, profile := range c.profiles {
+		if _, err := os.Stat(profile); err == nil {
+			files = append(files, profile)
+		}
+	}
+	if len(files) == 0 {
+		_, _ = fmt.Fprintln(stdout, "No coverage profiles were written")
+		return nil, nil, nil
+	}
+	profiles, err := readCoverageProfiles(files)
+	if err != nil {
+		return nil, nil, err
+	}
+	var groupProfiles map[string][]*cover.Profile
+	if c.reportsTags() {
+		groupFiles, err := c.groupProfiles(files)
+		if err != nil {
+			return nil, nil, err
+		}
+		if groupProfiles, err = tagCoverageProfiles(profiles, groupFiles); err != nil {
+			return nil, nil, err
+		}
+	}
+	if c.outputDir == "" {
+		covered, total := coveredStatements(profiles)
+		_, _ = fmt.Fprintf(stdout, "Coverage: %s of statements (%d/%d)\n", formatCoveragePercent(covered, total), covered, total)
+		return profiles, groupProfiles, nil
+	}
+	if err := writeMergedCoverage(c.projectDir, filepath.Join(c.outputDir, CoverageProfileFile), profiles, c.formats, stdout); err != nil {
+		return nil, nil, err
+	}
+	if groupProfiles != nil {
+		if err := writeTagCoverage(c.projectDir, c.outputDir, profiles, groupProfiles, stdout); err != nil {
+			return nil, nil, err
+		}
+	}
+	return profiles, groupProfiles, nil
+}
+
+// groupProfiles returns the provided profiles grouped by the coverage groups to which they are attributed (see
+// profileGroups).
+func (c *coverageCollector) groupProfiles(files []string) (map[string][]string, error) {
+	modPath, err := modulePath(c.projectDir)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := pkgTags(c.projectDir, c.param)
+	if err != nil {
+		return nil, err
+	}
+	groupFiles := make(map[string][]string)
+	for _, file := range files {
+		for _, group := range profileGroups(modPath, c.profilePkgs[file], tags) {
+			groupFiles[group] = append(groupFiles[group], file)
+		}
+	}
+	return groupFiles, nil
+}
+
+// cleanup removes the temporary directory to which the profiles of the commands are written.
+func (c *coverageCollector) cleanup() {
+	_ = os.RemoveAll(c.profilesDir)
+}
+
+// MergeCoverageProfiles merges the provided coverage profiles (written by "go test -coverprofile") into a single
+// profile that is written to the output file, and prints the coverage of the merged profile. An input that is a
+// directory refers to the CoverageProfileFile in the directory (for example, the coverage output directory of a
+// partition). The counts of a block that is in multiple profiles are added (or, in "set" mode, combined), so the
+// coverage of the merged profile is the union of the coverage of the profiles. All of the profiles must have the same
+// mode. Coverage reports in the provided formats for the packages in the module in the project directory are written to
+// the directory of the output file. The run fails if the merged profile does not meet the coverage minimums of the
+// param (see CoverageParam), so that the minimums are enforced for the combined coverage of all of the partitions of a
+// run. The profiles of the tests of the tags of the param in the input directories are merged and reported as well (see
+// writeTagCoverage), and the minimums of the tags are enforced for them.
+func MergeCoverageProfiles(projectDir, output string, inputs []string, formats []CoverageFormat, param TestParam, stdout io.Writer) error {
+	if err := param.Validate(); err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return errors.Errorf("no coverage profiles to merge")
+	}
+	var files []string
+	// groupFiles contains the profiles of the coverage groups (see writeTagCoverage) in the input directories
+	groupFiles := make(map[string][]string)
+	for _, input := range inputs {
+		if fi, err := os.Stat(input); err == nil && fi.IsDir() {
+			for _, group := range append(slices.Sorted(maps.Keys(param.Tags)), untaggedCoverageGroup) {
+				groupFile := filepath.Join(input, tagCoverageProfileFile(group))
+				if _, err := os.Stat(groupFile); err == nil {
+					groupFiles[group] = append(groupFiles[group], groupFile)
+				}
+			}
+			input = filepath.Join(input, CoverageProfileFile)
+		}
+		files = append(files, input)
+	}
+	profiles, err := readCoverageProfiles(files)
+	if err != nil {
+		return err
+	}
+	if err := writeMergedCoverage(projectDir, output, profiles, formats, stdout); err != nil {
+		return err
+	}
+	var groupProfiles map[string][]*cover.Profile
+	if len(groupFiles) > 0 {
+		if groupProfiles, err = tagCoverageProfiles(profiles, groupFiles); err != nil {
+			return err
+		}
+		if err := writeTagCoverage(projectDir, filepath.Dir(output), profiles, groupProfiles, stdout); err != nil {
+			return err
+		}
+	}
+	return checkCoverage(projectDir, profiles, groupProfiles, nil, param, stdout)
+}
+
+// writeMergedCoverage writes the provided merged profiles to the output file along with the coverage reports in the
+// provided formats, and prints the coverage of the profiles.
+func writeMergedCoverage(projectDir, output string, profiles []*cover.Profile, formats []CoverageFormat, stdout io.Writer) error {
+	var content bytes.Buffer
+	writeCoverageProfile(&content, profiles)
+	if err := os.WriteFile(output, content.Bytes(), 0644); err != nil {
+		return errors.Wrapf(err, "failed to write coverage profile")
+	}
+	covered, total := coveredStatements(profiles)
+	_, _ = fmt.Fprintf(stdout, "Coverage: %s of statements (%d/%d) written to %s\n", formatCoveragePercent(covered, total), covered, total, output)
+	return writeCoverageReports(filepath.Dir(output), projectDir, profiles, formats, stdout)
+}
+
+// readCoverageProfiles reads and merges the provided coverage profiles.
+func readCoverageProfiles(files []string) ([]*cover.Profile, error) {
+	var contents []profileContent
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read coverage profile")
+		}
+		contents = append(contents, profileContent{name: file, content: string(content)})
+	}
+	return mergeProfileContents(contents)
+}
+
+// unionCoverageProfiles merges the provided sets of profiles.
+func unionCoverageProfiles(sets ...[]*cover.Profile) ([]*cover.Profile, error) {
+	var contents []profileContent
+	for i, profiles := range sets {
+		var content bytes.Buffer
+		writeCoverageProfile(&content, profiles)
+		contents = append(contents, profileContent{name: fmt.Sprintf("#%d", i), content: content.String()})
+	}
+	return mergeProfileContents(contents)
+}
+
+// profileContent is the content of a coverage profile in the format written by "go test -coverprofile".
+type profileContent struct {
+	// name identifies the profile in errors.
+	name    string
+	content string
+}
+
+// mergeProfileContents parses and merges the provided coverage profiles.
+func mergeProfileContents(contents []profileContent) ([]*cover.Profile, error) {
+	// the profiles are concatenated without their mode lines (other than the first) so that cover.ParseProfiles
+	// merges the blocks that are in multiple profiles
+	var merged bytes.Buffer
+	mode := ""
+	for _, content := range contents {
+		modeLine, rest, _ := strings.Cut(content.content, "\n")
+		fileMode, ok := strings.CutPrefix(modeLine, "mode: ")
+		if !ok {
+			return nil, errors.Errorf("invalid coverage profile %s: the first line must specify the mode", content.name)
+		}
+		switch mode {
+		case "":
+			mode = fileMode
+			merged.WriteString(modeLine + "\n")
+		case fileMode:
+		default:
+			return nil, errors.Errorf("coverage profile %s has mode %q, but the other profiles have mode %q", content.name, fileMode, mode)
+		}
+		merged.WriteString(rest)
+		if rest != "" && !strings.HasSuffix(rest, "\n") {
+			merged.WriteString("\n")
+		}
+	}
+	profiles, err := cover.ParseProfilesFromReader(&merged)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse coverage profiles")
+	}
+	if len(profiles) == 0 {
+		// a profile without any blocks still has a mode
+		profiles = append(profiles, &cover.Profile{Mode: mode})
+	}
+	return profiles, nil
+}
+
+// uncoveredProfiles returns copies of the provided profiles in which no blocks are covered.
+func uncoveredProfiles(profiles []*cover.Profile) []*cover.Profile {
+	uncovered := make([]*cover.Profile, 0, len(profiles))
+	for _, profile := range profiles {
+		blocks := slices.Clone(profile.Blocks)
+		for i := range blocks {
+			blocks[i].Count = 0
+		}
+		uncovered = append(uncovered, &cover.Profile{FileName: profile.FileName, Mode: profile.Mode, Blocks: blocks})
+	}
+	return uncovered
+}
+
+// writeCoverageProfile writes the provided profiles in the format written by "go test -coverprofile". A profile
+// without a file name only contributes its mode.
+func writeCoverageProfile(w io.Writer, profiles []*cover.Profile) {
+	_, _ = fmt.Fprintf(w, "mode: %s\n", profiles[0].Mode)
+	for _, profile := range profiles {
+		for _, b := range profile.Blocks {
+			_, _ = fmt.Fprintf(w, "%s:%d.%d,%d.%d %d %d\n", profile.FileName, b.StartLine, b.StartCol, b.EndLine, b.EndCol, b.NumStmt, b.Count)
+		}
+	}
+}
+
+// coveredStatements returns the number of statements in the provided profiles that were executed and the total number
+// of statements.
+func coveredStatements(profiles []*cover.Profile) (covered, total int) {
+	for _, profile := range profiles {
+		for _, b := range profile.Blocks {
+			total += b.NumStmt
+			if b.Count > 0 {
+				covered += b.NumStmt
+			}
+		}
+	}
+	return covered, total
+}
+
+// formatCoveragePercent formats the percentage of covered statements in the format used by "go test -cover".
+func formatCoveragePercent(covered, total int) string {
+	if total == 0 {
+		return "0.0%"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(covered)/float64(total))
+}
+
+// hasTestFlag returns true if the provided "go test" arguments specify the "go test" flag with the provided name
+// before any "-args" flag.
+func hasTestFlag(testArgs []string, name string) bool {
+	for _, arg := range testArgs {
+		if arg == "-args" || arg == "--args" {
+			return false
+		}
+		flagName, _, _ := strings.Cut(strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-"), "=")
+		if strings.HasPrefix(arg, "-") && flagName == name {
+			return true
+		}
+	}
+	return false
+}
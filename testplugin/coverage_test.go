@@ -0,0 +1,120 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTestCmdCoverageOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeCoverageTestModule(t, tmpDir)
+
+	// every package is tested by its own command, so the profiles of the commands must be merged
+	var stdout bytes.Buffer
+	coverageDir := filepath.Join(tmpDir, "coverage")
+	err := RunTestCmd(tmpDir, []string{"-count=1"}, nil, "", nil, TestParam{Jobs: 2, CoverageOutput: coverageDir}, &stdout)
+	require.NoError(t, err, stdout.String())
+	assert.Contains(t, stdout.String(), "Coverage: 75.0% of statements (3/4) written to "+filepath.Join(coverageDir, CoverageProfileFile))
+
+	profiles, err := readCoverageProfiles([]string{filepath.Join(coverageDir, CoverageProfileFile)})
+	require.NoError(t, err)
+	// a.Sub is only covered by the tests of package b, and package c is included even though it has no tests
+	covered := make(map[string][]int)
+	for _, profile := range profiles {
+		for _, block := range profile.Blocks {
+			covered[profile.FileName] = append(covered[profile.FileName], block.Count)
+		}
+	}
+	assert.Equal(t, map[string][]int{
+		"testmod/a/a.go": {1, 1},
+		"testmod/b/b.go": {1},
+		"testmod/c/c.go": {0},
+	}, covered)
+
+	err = RunTestCmd(tmpDir, []string{"-coverprofile=cover.out"}, nil, "", nil, TestParam{CoverageOutput: coverageDir}, &stdout)
+	assert.EqualError(t, err, `"-coverprofile" cannot be provided to "go test" if a coverage output directory, coverage minimums or a coverage base are specified`)
+}
+
+// writeCoverageTestModule writes a module to the provided directory in which 3 of the 4 statements are covered by the
+// tests: package "a" has 2 statements (one of which is only covered by the tests of package "b"), package "b" has 1
+// statement and package "c" has 1 statement and no tests.
+func writeCoverageTestModule(t *testing.T, dir string) {
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module testmod\n\ngo 1.21\n"), 0644))
+	for file, content := range map[string]string{
+		"a/a.go":      "package a\n\nfunc Add(x, y int) int {\n\treturn x + y\n}\n\nfunc Sub(x, y int) int {\n\treturn x - y\n}\n",
+		"a/a_test.go": "package a\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {\n\tAdd(1, 2)\n}\n",
+		"b/b.go":      "package b\n\nimport \"testmod/a\"\n\nfunc Diff(x, y int) int {\n\treturn a.Sub(x, y)\n}\n",
+		"b/b_test.go": "package b\n\nimport \"testing\"\n\nfunc TestDiff(t *testing.T) {\n\tDiff(2, 1)\n}\n",
+		"c/c.go":      "package c\n\nfunc C() int {\n\treturn 1\n}\n",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, file)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(content), 0644))
+	}
+}
+
+func TestMergeCoverageProfiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "partition-0"), 0755))
+	for file, content := range map[string]string{
+		"partition-0/" + CoverageProfileFile: "mode: count\n" +
+			"pkg/a.go:3.10,5.2 2 1\n" +
+			"pkg/a.go:7.10,9.2 1 0\n",
+		"partition-1.out": "mode: count\n" +
+			"pkg/a.go:7.10,9.2 1 3\n" +
+			"pkg/a.go:3.10,5.2 2 2\n" +
+			"pkg/b.go:3.10,5.2 1 0\n",
+		"set.out": "mode: set\n",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, file), []byte(content), 0644))
+	}
+
+	var stdout bytes.Buffer
+	output := filepath.Join(tmpDir, "merged.out")
+	err := MergeCoverageProfiles(tmpDir, output, []string{filepath.Join(tmpDir, "partition-0"), filepath.Join(tmpDir, "partition-1.out")}, nil, TestParam{}, &stdout)
+	require.NoError(t, err)
+	content, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, "mode: count\n"+
+		"pkg/a.go:3.10,5.2 2 3\n"+
+		"pkg/a.go:7.10,9.2 1 3\n"+
+		"pkg/b.go:3.10,5.2 1 0\n",
+		string(content))
+	assert.Equal(t, "Coverage: 75.0% of statements (3/4) written to "+output+"\n", stdout.String())
+
+	err = MergeCoverageProfiles(tmpDir, output, []string{filepath.Join(tmpDir, "partition-1.out"), filepath.Join(tmpDir, "set.out")}, nil, TestParam{}, &stdout)
+	assert.EqualError(t, err, `coverage profile `+filepath.Join(tmpDir, "set.out")+` has mode "set", but the other profiles have mode "count"`)
+}
+
+func TestHasTestFlag(t *testing.T) {
+	for i, tc := range []struct {
+		args []string
+		want bool
+	}{
+		{args: []string{"-coverpkg=./..."}, want: true},
+		{args: []string{"-count=1", "--coverpkg", "./..."}, want: true},
+		{args: []string{"-coverprofile=cover.out"}, want: false},
+		{args: []string{"-args", "-coverpkg=./..."}, want: false},
+		{args: nil, want: false},
+	} {
+		assert.Equal(t, tc.want, hasTestFlag(tc.args, "coverpkg"), "Case %d", i)
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/cover"
+)
+
+// coverageShortfall is a coverage minimum that was not met.
+type coverageShortfall struct {
+	// scope is what the minimum applies to: "total", "package [import path]", "tag [name]" or "changed lines".
+	scope string
+	// unit is what is covered: "statements" or "lines".
+	unit           string
+	covered, total int
+	min            float64
+	// notMeasured is true if the coverage could not be measured (for example, because no coverage profile was written
+	// for the tests of a tag), in which case the minimum is not met.
+	notMeasured bool
+}
+
+func (s coverageShortfall) String() string {
+	if s.notMeasured {
+		return fmt.Sprintf("%s: coverage of %s was not measured, minimum %v%%", s.scope, s.unit, s.min)
+	}
+	percent := 100 * coverageRate(s.covered, s.total)
+	return fmt.Sprintf("%s: %.2f%% of %s (%d/%d), minimum %v%%, %.2f%% short", s.scope, percent, s.unit, s.covered, s.total, s.min, s.min-percent)
+}
+
+// checkCoverage checks the provided profiles of packages in the module in the provided project directory against the
+// coverage minimums of the provided param. The minimums of the tags are checked against the provided merged profiles
+// of the coverage groups of the tags (see tagCoverageProfiles). If the provided tags of the run are non-empty, only the
+// packages of those tags were tested, so only the minimums of those tags are checked. If the param specifies a
+// coverage base, the coverage of the lines that changed relative to it is printed and checked against the minimum for
+// changed lines. Returns an error that reports every minimum that is not met, or nil if all of the minimums are met.
+func checkCoverage(projectDir string, profiles []*cover.Profile, groupProfiles map[string][]*cover.Profile, runTags []string, param TestParam, stdout io.Writer) error {
+	if !param.Coverage.hasMins() && param.CoverageBase == "" {
+		return nil
+	}
+	report := newCoverageReport(projectDir, profiles)
+	shortfalls, err := coverageShortfalls(projectDir, report, profiles, groupProfiles, runTags, param)
+	if err != nil {
+		return err
+	}
+	if param.CoverageBase != "" {
+		changed, err := newChangedCoverage(projectDir, param.CoverageBase, report)
+		if err != nil {
+			return err
+		}
+		printChangedCoverage(stdout, changed)
+		if minCoverage := param.Coverage.ChangedMin; minCoverage > 0 && 100*coverageRate(changed.coveredLines, changed.lines) < minCoverage {
+			shortfalls = append(shortfalls, coverageShortfall{scope: "changed lines", unit: "lines", covered: changed.coveredLines, total: changed.lines, min: minCoverage})
+		}
+	}
+	if len(shortfalls) == 0 {
+		return nil
+	}
+	outputParts := []string{fmt.Sprintf("%d coverage minimum(s) not met:", len(shortfalls))}
+	for _, shortfall := range shortfalls {
+		outputParts = append(outputParts, shortfall.String())
+	}
+	return errors.New(strings.Join(outputParts, "\n\t"))
+}
+
+// coverageShortfalls returns the statement coverage minimums of the provided param that are not met: the total
+// minimum, then the minimums of the packages ordered by import path (which are checked against the provided report),
+// then the minimums of the tags ordered by name (which are checked against the provided merged profiles of the
+// coverage groups). If the provided tags of the run are non-empty, the total and package minimums are not checked and
+// only the minimums of the tags of the run are checked.
+func coverageShortfalls(projectDir string, report coverageReport, merged []*cover.Profile, groupProfiles map[string][]*cover.Profile, runTags []string, param TestParam) ([]coverageShortfall, error) {
+	var shortfalls []coverageShortfall
+	addIfShort := func(scope string, stats coverageStats, minCoverage float64) {
+		if minCoverage > 0 && 100*stats.StatementRate() < minCoverage {
+			shortfalls = append(shortfalls, coverageShortfall{scope: scope, unit: "statements", covered: stats.CoveredStatements, total: stats.Statements, min: minCoverage})
+		}
+	}
+
+	// a run for tags only tests a subset of the packages, so the coverage of the module and its packages is not
+	// representative
+	if len(runTags) == 0 {
+		addIfShort("total", report.coverageStats, param.Coverage.Min)
+		if len(param.Coverage.Packages) > 0 {
+			modPath, err := modulePath(projectDir)
+			if err != nil {
+				return nil, err
+			}
+			for _, pkg := range report.Packages {
+				relPath, ok := relPkgPath(modPath, pkg.Name)
+				if !ok {
+					continue
+				}
+				addIfShort("package "+pkg.Name, pkg.coverageStats, pkgCoverageMin(param.Coverage.Packages, strings.TrimPrefix(relPath, "./")))
+			}
+		}
+	}
+
+	for _, tag := range slices.Sorted(maps.Keys(param.Coverage.Tags)) {
+		minCoverage := param.Coverage.Tags[tag]
+		if minCoverage <= 0 || len(runTags) > 0 && !tagRun(tag, runTags) {
+			continue
+		}
+		profiles, err := tagMinProfiles(tag, merged, groupProfiles)
+		if err != nil {
+			return nil, err
+		}
+		covered, total := coveredStatements(profiles)
+		if total == 0 {
+			// the tests of the tag were not run or the module has no statements
+			shortfalls = append(shortfalls, coverageShortfall{scope: "tag " + tag, unit: "statements", min: minCoverage, notMeasured: true})
+			continue
+		}
+		addIfShort("tag "+tag, coverageStats{Statements: total, CoveredStatements: covered}, minCoverage)
+	}
+	return shortfalls, nil
+}
+
+// tagMinProfiles returns the profiles against which the minimum of the provided tag is checked: the merged profile of
+// the coverage group of the tag, the union of the profiles of all of the tags for AllTagName or the profile of the
+// packages that are not part of any tag for NoneTagName. Returns nil if no profile was written for the tests of the
+// tag.
+func tagMinProfiles(tag string, merged []*cover.Profile, groupProfiles map[string][]*cover.Profile) ([]*cover.Profile, error) {
+	switch tag {
+	case AllTagName:
+		if len(groupProfiles) == 0 || len(groupProfiles) == 1 && groupProfiles[untaggedCoverageGroup] != nil {
+			return nil, nil
+		}
+		return combinedTagCoverage(merged, groupProfiles)
+	case NoneTagName:
+		return groupProfiles[untaggedCoverageGroup], nil
+	default:
+		return groupProfiles[tag], nil
+	}
+}
+
+// tagRun returns true if the tests of the provided tag were run by a run for the provided tags.
+func tagRun(tag string, runTags []string) bool {
+	switch runTags[0] {
+	case AllTagName:
+		return tag != NoneTagName
+	case NoneTagName:
+		return tag == NoneTagName
+	default:
+		return slices.Contains(runTags, tag)
+	}
+}
+
+// pkgCoverageMin returns the highest minimum of the provided package minimums whose matcher matches the package with
+// the provided path relative to the module root ("." for the root package), or 0 if none of them match.
+func pkgCoverageMin(pkgMins []PackageCoverageMin, relPath string) float64 {
+	var minCoverage float64
+	for _, pkgMin := range pkgMins {
+		if pkgMin.Matcher != nil && pkgMin.Matcher.Match(relPath) {
+			minCoverage = max(minCoverage, pkgMin.Min)
+		}
+	}
+	return minCoverage
+}
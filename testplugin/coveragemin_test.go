@@ -0,0 +1,162 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/palantir/pkg/matcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTestCmdCoverageMins(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeCoverageTestModule(t, tmpDir)
+	// an integration test package only has test files, so its tests only cover the statements of other packages
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "it"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "it", "it_test.go"), []byte("package it\n\nimport (\n\t\"testing\"\n\n\t\"testmod/a\"\n)\n\nfunc TestSub(t *testing.T) {\n\ta.Sub(2, 1)\n}\n"), 0644))
+
+	for _, tc := range []struct {
+		name      string
+		coverage  CoverageParam
+		tags      []string
+		partition *Partition
+		wantErr   string
+	}{
+		{
+			name:     "total minimum met",
+			coverage: CoverageParam{Min: 75},
+		},
+		{
+			name:     "total minimum not met",
+			coverage: CoverageParam{Min: 80},
+			wantErr: "1 coverage minimum(s) not met:\n" +
+				"\ttotal: 75.00% of statements (3/4), minimum 80%, 5.00% short",
+		},
+		{
+			name: "package minimums",
+			coverage: CoverageParam{
+				Packages: []PackageCoverageMin{
+					{Matcher: matcher.Path("a", "b"), Min: 100},
+					{Matcher: matcher.Name("c"), Min: 0},
+					// the highest minimum of the matchers that match a package applies
+					{Matcher: matcher.Path("c"), Min: 50},
+				},
+			},
+			wantErr: "1 coverage minimum(s) not met:\n" +
+				"\tpackage testmod/c: 0.00% of statements (0/1), minimum 50%, 50.00% short",
+		},
+		{
+			name: "tag minimums",
+			coverage: CoverageParam{
+				Min:  90,
+				Tags: map[string]float64{"lib": 70, "Other": 90, "integration": 30},
+			},
+			wantErr: "4 coverage minimum(s) not met:\n" +
+				"\ttotal: 75.00% of statements (3/4), minimum 90%, 15.00% short\n" +
+				"\ttag integration: 25.00% of statements (1/4), minimum 30%, 5.00% short\n" +
+				"\ttag lib: 25.00% of statements (1/4), minimum 70%, 45.00% short\n" +
+				"\ttag other: 50.00% of statements (2/4), minimum 90%, 40.00% short",
+		},
+		{
+			name: "minimums of all and none tags",
+			coverage: CoverageParam{
+				Tags: map[string]float64{AllTagName: 100, NoneTagName: 1},
+			},
+			wantErr: "2 coverage minimum(s) not met:\n" +
+				"\ttag all: 75.00% of statements (3/4), minimum 100%, 25.00% short\n" +
+				"\ttag none: coverage of statements was not measured, minimum 1%",
+		},
+		{
+			name: "only the minimums of the tags of a run for tags are enforced",
+			coverage: CoverageParam{
+				Min:      100,
+				Packages: []PackageCoverageMin{{Matcher: matcher.Path("c"), Min: 100}},
+				Tags:     map[string]float64{"lib": 50, "other": 100, "integration": 100},
+			},
+			tags: []string{"lib"},
+			// the statements of the packages that are not linked by any of the tests of the run are not measured
+			wantErr: "1 coverage minimum(s) not met:\n" +
+				"\ttag lib: 33.33% of statements (1/3), minimum 50%, 16.67% short",
+		},
+		{
+			name: "minimums of the tags of a run for all tags",
+			coverage: CoverageParam{
+				Min:  100,
+				Tags: map[string]float64{AllTagName: 75, "other": 50, NoneTagName: 100},
+			},
+			tags: []string{AllTagName},
+		},
+		{
+			name:      "minimums are not enforced for a partition",
+			coverage:  CoverageParam{Min: 100},
+			partition: &Partition{Index: 0, Total: 1},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			param := TestParam{
+				Tags: map[string]matcher.Matcher{
+					"lib":         matcher.Path("a", "c"),
+					"other":       matcher.Path("b", "c"),
+					"integration": matcher.Path("it"),
+				},
+				Coverage: tc.coverage,
+			}
+			var stdout bytes.Buffer
+			err := RunTestCmd(tmpDir, nil, tc.tags, "", tc.partition, param, &stdout)
+			if tc.wantErr == "" {
+				require.NoError(t, err, stdout.String())
+				return
+			}
+			assert.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestMergeCoverageProfilesCoverageMins(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeCoverageTestModule(t, tmpDir)
+
+	// the partitions each write the coverage of their packages, and the minimums are enforced for the merged profile
+	tags := map[string]matcher.Matcher{
+		"lib":   matcher.Path("a"),
+		"other": matcher.Path("b"),
+	}
+	var stdout bytes.Buffer
+	var inputs []string
+	for i := range 2 {
+		coverageDir := filepath.Join(tmpDir, fmt.Sprintf("partition-%d", i))
+		err := RunTestCmd(tmpDir, nil, nil, "", &Partition{Index: i, Total: 2}, TestParam{Tags: tags, CoverageOutput: coverageDir, Coverage: CoverageParam{Min: 100}}, &stdout)
+		require.NoError(t, err, stdout.String())
+		inputs = append(inputs, coverageDir)
+	}
+	output := filepath.Join(tmpDir, CoverageProfileFile)
+	err := MergeCoverageProfiles(tmpDir, output, inputs, nil, TestParam{Coverage: CoverageParam{Min: 75}}, &stdout)
+	require.NoError(t, err)
+	err = MergeCoverageProfiles(tmpDir, output, inputs, nil, TestParam{Coverage: CoverageParam{Min: 80}}, &stdout)
+	assert.EqualError(t, err, "1 coverage minimum(s) not met:\n\ttotal: 75.00% of statements (3/4), minimum 80%, 5.00% short")
+
+	// the minimums of the tags are enforced for the merged profiles of the tests of the tags
+	err = MergeCoverageProfiles(tmpDir, output, inputs, nil, TestParam{Tags: tags, Coverage: CoverageParam{Tags: map[string]float64{"lib": 25, "other": 60}}}, &stdout)
+	assert.EqualError(t, err, "1 coverage minimum(s) not met:\n\ttag other: 50.00% of statements (2/4), minimum 60%, 10.00% short")
+
+	err = MergeCoverageProfiles(tmpDir, output, inputs, nil, TestParam{Coverage: CoverageParam{Min: 101}}, &stdout)
+	assert.EqualError(t, err, "coverage minimum must be between 0 and 100, got 101")
+}
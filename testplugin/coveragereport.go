@@ -0,0 +1,423 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/cover"
+)
+
+// CoverageFormat is a format of coverage report that is written along with the merged coverage profile.
+type CoverageFormat string
+
+const (
+	// CoverageFormatCobertura is a Cobertura XML report.
+	CoverageFormatCobertura CoverageFormat = "cobertura"
+	// CoverageFormatLCOV is an LCOV tracefile.
+	CoverageFormatLCOV CoverageFormat = "lcov"
+	// CoverageFormatHTML is an HTML report that lists the coverage of every package and file and shows the covered and
+	// uncovered lines of every file.
+	CoverageFormatHTML CoverageFormat = "html"
+)
+
+// coverageReportFiles contains the name of the file to which every format of coverage report is written.
+var coverageReportFiles = map[CoverageFormat]string{
+	CoverageFormatCobertura: "coverage.xml",
+	CoverageFormatLCOV:      "lcov.info",
+	CoverageFormatHTML:      "coverage.html",
+}
+
+// ParseCoverageFormat parses the provided coverage report format.
+func ParseCoverageFormat(s string) (CoverageFormat, error) {
+	switch format := CoverageFormat(s); format {
+	case CoverageFormatCobertura, CoverageFormatLCOV, CoverageFormatHTML:
+		return format, nil
+	default:
+		return "", errors.Errorf("invalid coverage format %q: must be one of %q, %q or %q", s, CoverageFormatCobertura, CoverageFormatLCOV, CoverageFormatHTML)
+	}
+}
+
+// coverageStats contains the number of statements and lines and the number of them that were covered.
+type coverageStats struct {
+	Statements, CoveredStatements int
+	Lines, CoveredLines           int
+}
+
+func (s *coverageStats) add(other coverageStats) {
+	s.Statements += other.Statements
+	s.CoveredStatements += other.CoveredStatements
+	s.Lines += other.Lines
+	s.CoveredLines += other.CoveredLines
+}
+
+// StatementRate returns the fraction of the statements that were covered (1 if there are no statements).
+func (s coverageStats) StatementRate() float64 {
+	return coverageRate(s.CoveredStatements, s.Statements)
+}
+
+// LineRate returns the fraction of the lines that were covered (1 if there are no lines).
+func (s coverageStats) LineRate() float64 {
+	return coverageRate(s.CoveredLines, s.Lines)
+}
+
+func coverageRate(covered, total int) float64 {
+	if total == 0 {
+		return 1
+	}
+	return float64(covered) / float64(total)
+}
+
+// coverageReport is the coverage of a set of profiles grouped by package and file, which is the model from which all
+// of the formats of coverage reports are written.
+type coverageReport struct {
+	coverageStats
+	// ProjectDir is the absolute path of the project directory.
+	ProjectDir string
+	Packages   []*coveragePkg
+}
+
+type coveragePkg struct {
+	coverageStats
+	// Name is the import path of the package.
+	Name  string
+	Files []*coverageFile
+}
+
+type coverageFile struct {
+	coverageStats
+	// Name is the name of the file in the coverage profile (the import path of its package followed by its name).
+	Name string
+	// Path is the path of the file relative to the project directory (or Name if the file is not in the module).
+	Path string
+	// Hits contains the number of times that every line that contains statements was executed keyed by line number.
+	// A line that contains multiple blocks is considered to be executed if any of them was executed.
+	Hits map[int]int
+}
+
+// sortedLines returns the numbers of the lines of the file that contain statements in ascending order.
+func (f *coverageFile) sortedLines() []int {
+	return slices.Sorted(maps.Keys(f.Hits))
+}
+
+// newCoverageReport returns the report for the provided profiles of packages in the module in the provided project
+// directory.
+func newCoverageReport(projectDir string, profiles []*cover.Profile) coverageReport {
+	report := coverageReport{
+		ProjectDir: projectDir,
+	}
+	if absProjectDir, err := filepath.Abs(projectDir); err == nil {
+		report.ProjectDir = absProjectDir
+	}
+	modPath, _ := modulePath(projectDir)
+	pkgs := make(map[string]*coveragePkg)
+	for _, profile := range profiles {
+		if profile.FileName == "" {
+			continue
+		}
+		file := &coverageFile{
+			Name: profile.FileName,
+			Path: profile.FileName,
+			Hits: make(map[int]int),
+		}
+		if rest, ok := strings.CutPrefix(profile.FileName, modPath+"/"); ok && modPath != "" {
+			file.Path = rest
+		}
+		for _, b := range profile.Blocks {
+			file.Statements += b.NumStmt
+			if b.Count > 0 {
+				file.CoveredStatements += b.NumStmt
+			}
+			lastLine := b.EndLine
+			if b.EndCol <= 1 && b.EndLine > b.StartLine {
+				// the block ends at the start of its last line, which does not contain any of its statements
+				lastLine--
+			}
+			for line := b.StartLine; line <= lastLine; line++ {
+				file.Hits[line] = max(file.Hits[line], b.Count)
+			}
+		}
+		file.Lines = len(file.Hits)
+		for _, count := range file.Hits {
+			if count > 0 {
+				file.CoveredLines++
+			}
+		}
+		pkgName := path.Dir(profile.FileName)
+		pkg, ok := pkgs[pkgName]
+		if !ok {
+			pkg = &coveragePkg{Name: pkgName}
+			pkgs[pkgName] = pkg
+			report.Packages = append(report.Packages, pkg)
+		}
+		pkg.Files = append(pkg.Files, file)
+		pkg.add(file.coverageStats)
+		report.add(file.coverageStats)
+	}
+	slices.SortFunc(report.Packages, func(a, b *coveragePkg) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	for _, pkg := range report.Packages {
+		slices.SortFunc(pkg.Files, func(a, b *coverageFile) int {
+			return cmp.Compare(a.Name, b.Name)
+		})
+	}
+	return report
+}
+
+// writeCoverageReports writes the reports in the provided formats for the provided profiles of packages in the module
+// in the provided project directory to the provided directory, and prints the files to which they were written.
+func writeCoverageReports(dir, projectDir string, profiles []*cover.Profile, formats []CoverageFormat, stdout io.Writer) error {
+	if len(formats) == 0 {
+		return nil
+	}
+	report := newCoverageReport(projectDir, profiles)
+	for _, format := range formats {
+		file := filepath.Join(dir, coverageReportFiles[format])
+		if err := writeCoverageReport(file, report, format); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(stdout, "Coverage report (%s) written to %s\n", format, file)
+	}
+	return nil
+}
+
+func writeCoverageReport(file string, report coverageReport, format CoverageFormat) (rErr error) {
+	f, err := os.Create(file)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s coverage report", format)
+	}
+	defer func() {
+		if err := f.Close(); err != nil && rErr == nil {
+			rErr = errors.Wrapf(err, "failed to close %s coverage report", format)
+		}
+	}()
+	switch format {
+	case CoverageFormatCobertura:
+		err = writeCobertura(f, report, time.Now())
+	case CoverageFormatLCOV:
+		err = writeLCOV(f, report)
+	case CoverageFormatHTML:
+		err = writeCoverageHTML(f, report)
+	}
+	return errors.Wrapf(err, "failed to write %s coverage report", format)
+}
+
+// The elements of a Cobertura XML report as defined by the coverage-04.dtd of Cobertura. Every package is a package and
+// every file is a class. Branches are not measured, so the branch rates are always 0.
+type (
+	coberturaCoverage struct {
+		XMLName         xml.Name           `xml:"coverage"`
+		LineRate        string             `xml:"line-rate,attr"`
+		BranchRate      string             `xml:"branch-rate,attr"`
+		LinesCovered    int                `xml:"lines-covered,attr"`
+		LinesValid      int                `xml:"lines-valid,attr"`
+		BranchesCovered int                `xml:"branches-covered,attr"`
+		BranchesValid   int                `xml:"branches-valid,attr"`
+		Complexity      string             `xml:"complexity,attr"`
+		Version         string             `xml:"version,attr"`
+		Timestamp       int64              `xml:"timestamp,attr"`
+		Sources         []string           `xml:"sources>source"`
+		Packages        []coberturaPackage `xml:"packages>package"`
+	}
+	coberturaPackage struct {
+		Name       string           `xml:"name,attr"`
+		LineRate   string           `xml:"line-rate,attr"`
+		BranchRate string           `xml:"branch-rate,attr"`
+		Complexity string           `xml:"complexity,attr"`
+		Classes    []coberturaClass `xml:"classes>class"`
+	}
+	coberturaClass struct {
+		Name       string          `xml:"name,attr"`
+		Filename   string          `xml:"filename,attr"`
+		LineRate   string          `xml:"line-rate,attr"`
+		BranchRate string          `xml:"branch-rate,attr"`
+		Complexity string          `xml:"complexity,attr"`
+		Methods    struct{}        `xml:"methods"`
+		Lines      []coberturaLine `xml:"lines>line"`
+	}
+	coberturaLine struct {
+		Number int `xml:"number,attr"`
+		Hits   int `xml:"hits,attr"`
+	}
+)
+
+func writeCobertura(w io.Writer, report coverageReport, timestamp time.Time) error {
+	coverage := coberturaCoverage{
+		LineRate:     formatRate(report.LineRate()),
+		BranchRate:   formatRate(0),
+		LinesCovered: report.CoveredLines,
+		LinesValid:   report.Lines,
+		Complexity:   formatRate(0),
+		Version:      "1.9",
+		Timestamp:    timestamp.UnixMilli(),
+		Sources:      []string{report.ProjectDir},
+	}
+	for _, pkg := range report.Packages {
+		coberturaPkg := coberturaPackage{
+			Name:       pkg.Name,
+			LineRate:   formatRate(pkg.LineRate()),
+			BranchRate: formatRate(0),
+			Complexity: formatRate(0),
+		}
+		for _, file := range pkg.Files {
+			class := coberturaClass{
+				Name:       file.Name,
+				Filename:   file.Path,
+				LineRate:   formatRate(file.LineRate()),
+				BranchRate: formatRate(0),
+				Complexity: formatRate(0),
+			}
+			for _, line := range file.sortedLines() {
+				class.Lines = append(class.Lines, coberturaLine{Number: line, Hits: file.Hits[line]})
+			}
+			coberturaPkg.Classes = append(coberturaPkg.Classes, class)
+		}
+		coverage.Packages = append(coverage.Packages, coberturaPkg)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(coverage); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func formatRate(rate float64) string {
+	return strconv.FormatFloat(rate, 'f', 4, 64)
+}
+
+// writeLCOV writes the report as an LCOV tracefile that contains the hits of every line of every file.
+func writeLCOV(w io.Writer, report coverageReport) error {
+	for _, pkg := range report.Packages {
+		for _, file := range pkg.Files {
+			lines := []string{"TN:", "SF:" + file.Path}
+			for _, line := range file.sortedLines() {
+				lines = append(lines, fmt.Sprintf("DA:%d,%d", line, file.Hits[line]))
+			}
+			lines = append(lines, fmt.Sprintf("LF:%d", file.Lines), fmt.Sprintf("LH:%d", file.CoveredLines), "end_of_record")
+			if _, err := fmt.Fprintln(w, strings.Join(lines, "\n")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// coverageHTMLLine is a line of source in the HTML report. Class is "covered" or "uncovered" for lines that contain
+// statements and empty otherwise.
+type coverageHTMLLine struct {
+	Number int
+	Text   string
+	Class  string
+}
+
+var coverageHTMLTemplate = template.Must(template.New("coverage").Funcs(template.FuncMap{
+	"percent": func(rate float64) string {
+		return fmt.Sprintf("%.1f%%", 100*rate)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Coverage report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { padding: 0.2em 1em; text-align: left; }
+th, tr.package td { border-top: 1px solid #ccc; font-weight: bold; }
+td.rate { text-align: right; font-family: monospace; }
+pre { border: 1px solid #ccc; padding: 0.5em; }
+pre span { display: block; }
+.number { color: #999; display: inline-block; width: 4em; text-align: right; margin-right: 1em; }
+.covered { background: #dfd; }
+.uncovered { background: #fdd; }
+</style>
+</head>
+<body>
+<h1>Coverage: {{percent .Report.StatementRate}} of statements, {{percent .Report.LineRate}} of lines</h1>
+<table>
+<tr><th>Package / file</th><th>Statements</th><th>Lines</th></tr>
+{{- range .Report.Packages}}
+<tr class="package"><td>{{.Name}}</td><td class="rate">{{percent .StatementRate}} ({{.CoveredStatements}}/{{.Statements}})</td><td class="rate">{{percent .LineRate}} ({{.CoveredLines}}/{{.Lines}})</td></tr>
+{{- range .Files}}
+<tr><td><a href="#{{.Path}}">{{.Path}}</a></td><td class="rate">{{percent .StatementRate}} ({{.CoveredStatements}}/{{.Statements}})</td><td class="rate">{{percent .LineRate}} ({{.CoveredLines}}/{{.Lines}})</td></tr>
+{{- end}}
+{{- end}}
+</table>
+{{- range .Files}}
+<h2 id="{{.Path}}">{{.Path}}</h2>
+{{- if .Lines}}
+<pre>{{range .Lines}}<span class="{{.Class}}"><span class="number">{{.Number}}</span>{{.Text}}</span>{{end}}</pre>
+{{- else}}
+<p>The source of the file is not available.</p>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
+
+// writeCoverageHTML writes the report as an HTML page that contains the coverage of every package and file followed by
+// the source of every file with its covered and uncovered lines highlighted.
+func writeCoverageHTML(w io.Writer, report coverageReport) error {
+	type htmlFile struct {
+		Path  string
+		Lines []coverageHTMLLine
+	}
+	var files []htmlFile
+	for _, pkg := range report.Packages {
+		for _, file := range pkg.Files {
+			hf := htmlFile{Path: file.Path}
+			// the source of files that are not in the project directory is not shown
+			if src, err := os.ReadFile(filepath.Join(report.ProjectDir, filepath.FromSlash(file.Path))); err == nil && file.Path != file.Name {
+				for i, text := range strings.Split(strings.TrimSuffix(string(src), "\n"), "\n") {
+					line := coverageHTMLLine{Number: i + 1, Text: text}
+					if hits, ok := file.Hits[i+1]; ok {
+						line.Class = "uncovered"
+						if hits > 0 {
+							line.Class = "covered"
+						}
+					}
+					hf.Lines = append(hf.Lines, line)
+				}
+			}
+			files = append(files, hf)
+		}
+	}
+	return coverageHTMLTemplate.Execute(w, struct {
+		Report coverageReport
+		Files  []htmlFile
+	}{
+		Report: report,
+		Files:  files,
+	})
+}
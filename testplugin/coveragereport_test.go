@@ -0,0 +1,99 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/cover"
+)
+
+func TestCoverageReports(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module testmod\n\ngo 1.21\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "foo"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "foo", "foo.go"), []byte(`package foo
+
+func Foo(x int) int {
+	if x > 0 {
+		return x
+	}
+	return -x
+}
+`), 0644))
+	report := newCoverageReport(tmpDir, []*cover.Profile{
+		{
+			FileName: "testmod/foo/foo.go",
+			Mode:     "count",
+			Blocks: []cover.ProfileBlock{
+				{StartLine: 4, StartCol: 2, EndLine: 4, EndCol: 11, NumStmt: 1, Count: 3},
+				{StartLine: 4, StartCol: 11, EndLine: 6, EndCol: 3, NumStmt: 1, Count: 3},
+				{StartLine: 7, StartCol: 2, EndLine: 8, EndCol: 1, NumStmt: 1, Count: 0},
+			},
+		},
+		{
+			FileName: "testmod/foo/bar/bar.go",
+			Mode:     "count",
+			Blocks: []cover.ProfileBlock{
+				{StartLine: 3, StartCol: 2, EndLine: 5, EndCol: 2, NumStmt: 2, Count: 0},
+			},
+		},
+	})
+
+	assert.Equal(t, coverageStats{Statements: 5, CoveredStatements: 2, Lines: 7, CoveredLines: 3}, report.coverageStats)
+	require.Len(t, report.Packages, 2)
+	assert.Equal(t, "testmod/foo", report.Packages[0].Name)
+	assert.Equal(t, "testmod/foo/bar", report.Packages[1].Name)
+	foo := report.Packages[0].Files[0]
+	assert.Equal(t, "foo/foo.go", foo.Path)
+	// the last block ends at the start of line 8, which is not part of it
+	assert.Equal(t, map[int]int{4: 3, 5: 3, 6: 3, 7: 0}, foo.Hits)
+	assert.Equal(t, 2.0/3.0, foo.StatementRate())
+	assert.Equal(t, 0.75, foo.LineRate())
+
+	var lcov bytes.Buffer
+	require.NoError(t, writeLCOV(&lcov, report))
+	assert.Equal(t, "TN:\nSF:foo/foo.go\nDA:4,3\nDA:5,3\nDA:6,3\nDA:7,0\nLF:4\nLH:3\nend_of_record\n"+
+		"TN:\nSF:foo/bar/bar.go\nDA:3,0\nDA:4,0\nDA:5,0\nLF:3\nLH:0\nend_of_record\n", lcov.String())
+
+	var cobertura bytes.Buffer
+	require.NoError(t, writeCobertura(&cobertura, report, time.UnixMilli(1000)))
+	var coverage coberturaCoverage
+	require.NoError(t, xml.Unmarshal(cobertura.Bytes(), &coverage))
+	assert.Equal(t, "0.4286", coverage.LineRate)
+	assert.Equal(t, 3, coverage.LinesCovered)
+	assert.Equal(t, 7, coverage.LinesValid)
+	assert.Equal(t, int64(1000), coverage.Timestamp)
+	require.Len(t, coverage.Packages, 2)
+	assert.Equal(t, "0.7500", coverage.Packages[0].LineRate)
+	assert.Equal(t, "foo/foo.go", coverage.Packages[0].Classes[0].Filename)
+	assert.Equal(t, []coberturaLine{{4, 3}, {5, 3}, {6, 3}, {7, 0}}, coverage.Packages[0].Classes[0].Lines)
+
+	var html bytes.Buffer
+	require.NoError(t, writeCoverageHTML(&html, report))
+	assert.Contains(t, html.String(), "<h1>Coverage: 40.0% of statements, 42.9% of lines</h1>")
+	assert.Contains(t, html.String(), `<span class="covered"><span class="number">5</span>		return x</span>`)
+	assert.Contains(t, html.String(), `<span class="uncovered"><span class="number">7</span>	return -x</span>`)
+	assert.Contains(t, html.String(), `<span class=""><span class="number">8</span>}</span>`)
+	// the source of bar.go does not exist
+	assert.Contains(t, html.String(), "<h2 id=\"foo/bar/bar.go\">foo/bar/bar.go</h2>\n<p>The source of the file is not available.</p>")
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"strings"
+)
+
+// The kinds of crashes that are recognized in the output of tests.
+const (
+	crashPanic = "panic"
+	crashRace  = "race"
+)
+
+// crash is a panic or a data race report in the output of a test or package.
+type crash struct {
+	// Kind is crashPanic or crashRace.
+	Kind string
+	// Output contains the lines of the panic (the panic message followed by the goroutine stack traces) or of the data
+	// race report.
+	Output []string
+}
+
+// Message returns the first line of the crash: the panic message for a panic, or the data race warning for a data race.
+func (c crash) Message() string {
+	for _, line := range c.Output {
+		if line = strings.TrimSpace(line); line != "" && !isRaceReportDelimiter(line) {
+			return line
+		}
+	}
+	return ""
+}
+
+// primaryCrash returns the crash that caused a test to fail out of the provided crashes of the test: the first panic,
+// or the first data race if the test did not panic.
+func primaryCrash(crashes []crash) crash {
+	for _, c := range crashes {
+		if c.Kind == crashPanic {
+			return c
+		}
+	}
+	return crashes[0]
+}
+
+// extractCrashes returns the panics and data race reports in the provided output. A panic is the last thing that a
+// test binary writes before it exits, so the panic extends to the end of the output (excluding the lines written by
+// the go command after the test binary exited). A data race report is enclosed in delimiter lines.
+func extractCrashes(output []string) []crash {
+	var crashes []crash
+	for i := 0; i < len(output); i++ {
+		switch {
+		case isPanicLine(output[i]):
+			end := len(output)
+			for j := i + 1; j < len(output); j++ {
+				if strings.HasPrefix(output[j], "FAIL\t") || strings.HasPrefix(output[j], "exit status ") {
+					end = j
+					break
+				}
+			}
+			crashes = append(crashes, crash{Kind: crashPanic, Output: output[i:end]})
+			i = end
+		case isRaceReportDelimiter(output[i]) && i+1 < len(output) && output[i+1] == "WARNING: DATA RACE":
+			end := len(output)
+			for j := i + 2; j < len(output); j++ {
+				if isRaceReportDelimiter(output[j]) {
+					end = j + 1
+					break
+				}
+			}
+			crashes = append(crashes, crash{Kind: crashRace, Output: output[i:end]})
+			i = end - 1
+		}
+	}
+	return crashes
+}
+
+func isRaceReportDelimiter(line string) bool {
+	return line == "=================="
+}
+
+// crashes returns the crashes in the output of the package and its tests keyed by the name of the test to which they
+// are attributed. The crashes in the output of the package that is not attributed to any test are keyed by the empty
+// string. When a subtest panics, the subtest completes and the panic is written as part of the output of its parent,
+// so a panic in the output of a test that has failed subtests is attributed to the last of the subtests that failed.
+func (p *pkgResult) crashes() map[string][]crash {
+	crashes := make(map[string][]crash)
+	if pkgCrashes := extractCrashes(p.Output); len(pkgCrashes) > 0 {
+		crashes[""] = pkgCrashes
+	}
+	for _, test := range p.Tests {
+		for _, c := range extractCrashes(test.Output) {
+			name := test.Name
+			if c.Kind == crashPanic {
+				if subtest := p.lastFailedSubtest(test.Name); subtest != "" {
+					name = subtest
+				}
+			}
+			crashes[name] = append(crashes[name], c)
+		}
+	}
+	return crashes
+}
+
+// lastFailedSubtest returns the name of the last subtest (at any depth) of the provided test that failed, or an empty
+// string if none of its subtests failed.
+func (p *pkgResult) lastFailedSubtest(test string) string {
+	var last string
+	for _, other := range p.Tests {
+		if other.Status == actionFail && strings.HasPrefix(other.Name, test+"/") {
+			last = other.Name
+		}
+	}
+	return last
+}
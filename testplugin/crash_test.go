@@ -0,0 +1,99 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPkgResultCrashes(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		output string
+		want   map[string][]crash
+	}{
+		{
+			name: "panic in subtest is attributed to the subtest",
+			output: `{"Action":"run","Package":"testmod/p","Test":"TestPanic"}
+{"Action":"run","Package":"testmod/p","Test":"TestPanic/sub"}
+{"Action":"output","Package":"testmod/p","Test":"TestPanic/sub","Output":"--- FAIL: TestPanic/sub (0.00s)\n"}
+{"Action":"fail","Package":"testmod/p","Test":"TestPanic/sub","Elapsed":0}
+{"Action":"output","Package":"testmod/p","Test":"TestPanic","Output":"--- FAIL: TestPanic (0.00s)\n"}
+{"Action":"output","Package":"testmod/p","Test":"TestPanic","Output":"panic: boom [recovered, repanicked]\n"}
+{"Action":"output","Package":"testmod/p","Test":"TestPanic","Output":"\n"}
+{"Action":"output","Package":"testmod/p","Test":"TestPanic","Output":"goroutine 8 [running]:\n"}
+{"Action":"output","Package":"testmod/p","Test":"TestPanic","Output":"testmod/p.TestPanic.func1()\n"}
+{"Action":"output","Package":"testmod/p","Test":"TestPanic","Output":"\t/project/p/p_test.go:4 +0x32\n"}
+{"Action":"fail","Package":"testmod/p","Test":"TestPanic","Elapsed":0}
+{"Action":"output","Package":"testmod/p","Output":"FAIL\ttestmod/p\t0.018s\n"}
+{"Action":"fail","Package":"testmod/p","Elapsed":0.019}
+`,
+			want: map[string][]crash{
+				"TestPanic/sub": {{
+					Kind:   crashPanic,
+					Output: []string{"panic: boom [recovered, repanicked]", "", "goroutine 8 [running]:", "testmod/p.TestPanic.func1()", "\t/project/p/p_test.go:4 +0x32"},
+				}},
+			},
+		},
+		{
+			name: "data races and package panic",
+			output: `{"Action":"run","Package":"testmod/r","Test":"TestRace"}
+{"Action":"output","Package":"testmod/r","Test":"TestRace","Output":"==================\n"}
+{"Action":"output","Package":"testmod/r","Test":"TestRace","Output":"WARNING: DATA RACE\n"}
+{"Action":"output","Package":"testmod/r","Test":"TestRace","Output":"Read at 0x00c0000182b8 by goroutine 8:\n"}
+{"Action":"output","Package":"testmod/r","Test":"TestRace","Output":"      /project/r/r_test.go:3 +0x2e\n"}
+{"Action":"output","Package":"testmod/r","Test":"TestRace","Output":"==================\n"}
+{"Action":"output","Package":"testmod/r","Test":"TestRace","Output":"    testing.go:1865: race detected during execution of test\n"}
+{"Action":"fail","Package":"testmod/r","Test":"TestRace","Elapsed":0.01}
+{"Action":"output","Package":"testmod/r","Output":"panic: test timed out after 1s\n"}
+{"Action":"output","Package":"testmod/r","Output":"\trunning tests:\n"}
+{"Action":"output","Package":"testmod/r","Output":"FAIL\ttestmod/r\t1.026s\n"}
+{"Action":"fail","Package":"testmod/r","Elapsed":1.026}
+`,
+			want: map[string][]crash{
+				"TestRace": {{
+					Kind:   crashRace,
+					Output: []string{"==================", "WARNING: DATA RACE", "Read at 0x00c0000182b8 by goroutine 8:", "      /project/r/r_test.go:3 +0x2e", "=================="},
+				}},
+				"": {{
+					Kind:   crashPanic,
+					Output: []string{"panic: test timed out after 1s", "\trunning tests:"},
+				}},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			results := newTestResults()
+			for line := range strings.Lines(tc.output) {
+				ev, ok := parseTestEvent(line)
+				assert.True(t, ok, line)
+				results.process(ev)
+			}
+			crashes := results.pkgs[0].crashes()
+			assert.Equal(t, tc.want, crashes)
+
+			locator := failureLocator{projectDir: "/project", modPath: "testmod"}
+			for _, failure := range locator.testFailures(results.pkgs[0]) {
+				assert.NotEmpty(t, failure.Crash, failure.Test)
+				if failure.Test != "" {
+					assert.Regexp(t, `^[pr]/[pr]_test\.go:\d+$`, failure.Location, failure.Test)
+				}
+			}
+		})
+	}
+}
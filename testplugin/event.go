@@ -0,0 +1,93 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Actions of the events emitted by "go test -json". Refer to "go doc test2json" for the meaning of the test actions.
+// The "build-output" and "build-fail" actions are emitted by the go command (rather than by test2json) for the
+// packages that fail to build.
+const (
+	actionStart       = "start"
+	actionRun         = "run"
+	actionPass        = "pass"
+	actionFail        = "fail"
+	actionOutput      = "output"
+	actionSkip        = "skip"
+	actionBuildOutput = "build-output"
+	actionBuildFail   = "build-fail"
+)
+
+// testEvent is a single event emitted by "go test -json".
+type testEvent struct {
+	Time    time.Time
+	Action  string
+	Package string
+	Test    string
+	// Elapsed is the duration in seconds of the test or package. Only set for "pass", "fail" and "skip" events.
+	Elapsed float64
+	Output  string
+	// OutputType is set by newer versions of test2json to distinguish the lines generated by the testing framework
+	// ("frame") from the output of the test itself.
+	OutputType string
+	// ImportPath is the package to which a "build-output" or "build-fail" event applies.
+	ImportPath string
+	// FailedBuild is set on a package "fail" event if the package failed because of a build failure, in which case it
+	// is the ImportPath of the "build-output" events that contain the build errors.
+	FailedBuild string
+}
+
+// parseTestEvent parses the provided line of "go test -json" output. Returns false if the line is not a JSON event
+// (the go command may write lines that are not JSON, such as errors for invalid flags or arguments).
+func parseTestEvent(line string) (testEvent, bool) {
+	if !strings.HasPrefix(line, "{") {
+		return testEvent{}, false
+	}
+	var ev testEvent
+	if err := json.Unmarshal([]byte(line), &ev); err != nil || ev.Action == "" {
+		return testEvent{}, false
+	}
+	return ev, true
+}
+
+// isTerminal returns true if the event reports the final result of a test or package.
+func (ev testEvent) isTerminal() bool {
+	switch ev.Action {
+	case actionPass, actionFail, actionSkip:
+		return true
+	}
+	return false
+}
+
+// elapsed returns the Elapsed value of the event as a time.Duration.
+func (ev testEvent) elapsed() time.Duration {
+	return secondsDuration(ev.Elapsed)
+}
+
+// isFrameLine returns true if the provided line of test output was generated by the testing framework to mark the
+// start, pause, continuation or end of a test (for example, "=== RUN   TestFoo" or "--- PASS: TestFoo (0.00s)").
+func isFrameLine(line string) bool {
+	trimmed := strings.TrimLeft(line, " ")
+	for _, prefix := range []string{"=== RUN", "=== PAUSE", "=== CONT", "=== NAME", "--- PASS:", "--- FAIL:", "--- SKIP:"} {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+	return false
+}
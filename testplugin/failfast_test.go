@@ -0,0 +1,47 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTestCmdFailFast(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module testmod\n\ngo 1.21\n"), 0644))
+	for pkg, content := range map[string]string{
+		"a": "package a\n\nimport \"testing\"\n\nfunc TestFail(t *testing.T) { t.Fatal(\"boom\") }\n",
+		"b": "package b\n\nimport \"testing\"\n\nfunc TestPass(t *testing.T) {}\n",
+		"c": "package c\n\nimport \"testing\"\n\nfunc TestPass(t *testing.T) {}\n",
+	} {
+		pkgDir := filepath.Join(tmpDir, pkg)
+		require.NoError(t, os.MkdirAll(pkgDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(pkgDir, pkg+"_test.go"), []byte(content), 0644))
+	}
+
+	// a single job tests the packages one at a time in order, so the failure of the first package stops the others
+	// from being tested. The failed test is not retried.
+	var stdout bytes.Buffer
+	err := RunTestCmd(tmpDir, []string{"-count=1"}, nil, "", nil, TestParam{Jobs: 1, Retries: 2, FailFast: true}, &stdout)
+	assert.EqualError(t, err, "1 package(s) had failing tests:\n\ttestmod/a\n\t\ttestmod/a.TestFail: a/a_test.go:5\n2 package(s) were not tested because of --fail-fast:\n\t./b\n\t./c")
+	assert.NotContains(t, stdout.String(), "testmod/b")
+	assert.NotContains(t, stdout.String(), "Retrying")
+}
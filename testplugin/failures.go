@@ -0,0 +1,180 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// testFailure is a failed test along with the location of its first failure.
+type testFailure struct {
+	// Test is the full name of the test, including the names of parent tests for subtests. Empty for a crash of the
+	// package that is not attributed to any test.
+	Test string
+	// Location is the location of the first failure in the format "file.go:123", where the file is relative to the
+	// project directory. Empty if the location could not be determined.
+	Location string
+	// Crash is the kind of crash (crashPanic or crashRace) that caused the test to fail, in which case Location is
+	// the location of the innermost frame of the panicking goroutine or of the first access of the data race that is
+	// in the project directory. Empty if the test did not crash.
+	Crash string
+	// Message is the first line of the message of the first failure: the message written by t.Error or one of its
+	// variants, or the message of the crash (see crash.Message). Empty if the message could not be determined.
+	Message string
+}
+
+// crashLabels contains the label of every kind of crash in the description of a failure.
+var crashLabels = map[string]string{
+	crashPanic: "panic",
+	crashRace:  "data race",
+}
+
+func (f testFailure) String(pkg string) string {
+	name := pkg
+	if f.Test != "" {
+		name += "." + f.Test
+	}
+	switch {
+	case f.Location == "" && f.Crash != "":
+		return name + ": " + crashLabels[f.Crash]
+	case f.Location == "":
+		return name
+	case f.Crash != "":
+		return name + ": " + crashLabels[f.Crash] + " at " + f.Location
+	default:
+		return name + ": " + f.Location
+	}
+}
+
+var (
+	// failureLocationRegexp matches the lines written by t.Error and its variants, which are prefixed with the name of
+	// the file (without its directory) and the line number of the call and indented based on the depth of the test.
+	failureLocationRegexp = regexp.MustCompile(`^\s+([^\s:]+\.go):(\d+): `)
+	// stackFrameLocationRegexp matches the lines of a goroutine stack trace that contain the location of a frame.
+	// The frames of data race reports are indented with spaces rather than a tab.
+	stackFrameLocationRegexp = regexp.MustCompile(`^\s+(.+\.go):(\d+)(?: \+0x[0-9a-f]+)?$`)
+)
+
+// failureLocator determines the locations of test failures relative to the project directory.
+type failureLocator struct {
+	// projectDir is the absolute path of the project directory.
+	projectDir string
+	// modPath is the path of the module in the project directory. If empty, the files of t.Error locations are not
+	// resolved relative to the project directory.
+	modPath string
+}
+
+func newFailureLocator(projectDir string) failureLocator {
+	var l failureLocator
+	if absProjectDir, err := filepath.Abs(projectDir); err == nil {
+		l.projectDir = absProjectDir
+	}
+	if modPath, err := modulePath(projectDir); err == nil {
+		l.modPath = modPath
+	}
+	return l
+}
+
+// testFailures returns the failures of the tests in the provided package that failed or crashed, followed by a
+// failure without a test if the package crashed outside of any test (for example, in TestMain or because of a test
+// timeout). If a test failed because any of its subtests failed, only the subtests are returned.
+func (l failureLocator) testFailures(pkg *pkgResult) []testFailure {
+	crashes := pkg.crashes()
+	var failures []testFailure
+	for _, test := range pkg.Tests {
+		if test.Status != actionFail && len(crashes[test.Name]) == 0 || pkg.lastFailedSubtest(test.Name) != "" {
+			continue
+		}
+		failures = append(failures, l.testFailure(pkg.Name, test, crashes[test.Name]))
+	}
+	if pkgCrashes := crashes[""]; len(pkgCrashes) > 0 {
+		failures = append(failures, l.crashFailure("", pkgCrashes))
+	}
+	return failures
+}
+
+// testFailure returns the failure of the provided test. If the test crashed, the first panic (or, if it did not
+// panic, the first data race) determines the location of the failure; otherwise, the first line written by t.Error
+// or one of its variants does.
+func (l failureLocator) testFailure(pkgName string, test *testResult, crashes []crash) testFailure {
+	if len(crashes) > 0 {
+		return l.crashFailure(test.Name, crashes)
+	}
+	failure := testFailure{
+		Test: test.Name,
+	}
+	for _, line := range test.Output {
+		if match := failureLocationRegexp.FindStringSubmatch(line); match != nil {
+			failure.Location = fmt.Sprintf("%s:%s", l.pkgFile(pkgName, match[1]), match[2])
+			failure.Message = line[len(match[0]):]
+			break
+		}
+	}
+	return failure
+}
+
+func (l failureLocator) crashFailure(test string, crashes []crash) testFailure {
+	c := primaryCrash(crashes)
+	return testFailure{
+		Test:     test,
+		Location: l.stackLocation(c.Output),
+		Crash:    c.Kind,
+		Message:  c.Message(),
+	}
+}
+
+// isPanicLine returns true if the provided line of output is the first line written by the runtime when a goroutine
+// panics (including when a test times out).
+func isPanicLine(line string) bool {
+	return strings.HasPrefix(line, "panic: ")
+}
+
+// stackLocation returns the location of the first frame in the provided stack traces that is in the project directory
+// (excluding vendored packages). The frames of a panicking goroutine are listed from the innermost frame, so this is
+// the innermost frame of the project.
+func (l failureLocator) stackLocation(stack []string) string {
+	if l.projectDir == "" {
+		return ""
+	}
+	for _, line := range stack {
+		match := stackFrameLocationRegexp.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		relPath, err := filepath.Rel(l.projectDir, match[1])
+		relPath = filepath.ToSlash(relPath)
+		if err != nil || relPath == ".." || strings.HasPrefix(relPath, "../") || strings.HasPrefix(relPath, "vendor/") {
+			continue
+		}
+		return fmt.Sprintf("%s:%s", relPath, match[2])
+	}
+	return ""
+}
+
+// pkgFile returns the path relative to the project directory of the provided file in the provided package. The file
+// is returned unmodified if the package is not in the module of the project directory.
+func (l failureLocator) pkgFile(pkgName, file string) string {
+	if l.modPath == "" {
+		return file
+	}
+	relPath, ok := relPkgPath(l.modPath, pkgName)
+	if !ok {
+		return file
+	}
+	return filepath.ToSlash(filepath.Join(relPath, file))
+}
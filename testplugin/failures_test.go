@@ -0,0 +1,68 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTestCmdReportsFailureLocations(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module testmod\n\ngo 1.21\n"), 0644))
+	for file, content := range map[string]string{
+		"root_test.go": `package root
+
+import "testing"
+
+func TestRoot(t *testing.T) {
+	t.Error("boom")
+}
+`,
+		"foo/foo_test.go": `package foo
+
+import "testing"
+
+func TestSub(t *testing.T) {
+	t.Run("pass", func(t *testing.T) {})
+	t.Run("fail", func(t *testing.T) {
+		t.Fatal("boom")
+	})
+}
+
+func TestPanic(t *testing.T) {
+	var m map[string]int
+	m["key"] = 1
+}
+`,
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(tmpDir, file)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, file), []byte(content), 0644))
+	}
+
+	var stdout bytes.Buffer
+	err := RunTestCmd(tmpDir, []string{"-count=1"}, nil, "", nil, TestParam{}, &stdout)
+	assert.EqualError(t, err, `2 package(s) had failing tests:
+	testmod
+		testmod.TestRoot: root_test.go:6
+	testmod/foo
+		testmod/foo.TestSub/fail: foo/foo_test.go:8
+		testmod/foo.TestPanic: panic at foo/foo_test.go:14`)
+}
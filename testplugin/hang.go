@@ -0,0 +1,66 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// writeGoroutineDumps writes the goroutine dumps of every package in the results that hung to a file in the provided
+// directory and records the file in the result of the package. If the directory is empty, a new temporary directory
+// is used. A message that identifies the file is printed for every package.
+func writeGoroutineDumps(artifactsDir string, results *testResults, stdout io.Writer) error {
+	hungPkgs := results.hungPkgs()
+	if len(hungPkgs) == 0 {
+		return nil
+	}
+	if artifactsDir == "" {
+		dir, err := os.MkdirTemp("", "godel-test-artifacts-")
+		if err != nil {
+			return errors.Wrapf(err, "failed to create artifacts directory")
+		}
+		artifactsDir = dir
+	} else if err := os.MkdirAll(artifactsDir, 0755); err != nil {
+		return errors.Wrapf(err, "failed to create artifacts directory")
+	}
+
+	for _, pkgName := range hungPkgs {
+		pkg := results.byName[pkgName]
+		var lines []string
+		for _, test := range pkg.Tests {
+			if test.Hung {
+				lines = append(lines, fmt.Sprintf("=== goroutine dump written while %s was running:", test.Name))
+				lines = append(lines, test.GoroutineDump...)
+			}
+		}
+		if len(pkg.GoroutineDump) > 0 {
+			lines = append(lines, fmt.Sprintf("=== output of %s written after it was sent SIGQUIT:", pkg.Name))
+			lines = append(lines, pkg.GoroutineDump...)
+		}
+		dumpFile := filepath.Join(artifactsDir, strings.ReplaceAll(pkg.Name, "/", "_")+".goroutines.txt")
+		if err := os.WriteFile(dumpFile, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+			return errors.Wrapf(err, "failed to write goroutine dump")
+		}
+		pkg.GoroutineDumpFile = dumpFile
+		_, _ = fmt.Fprintf(stdout, "Goroutine dump of hung package %s written to %s\n", pkg.Name, dumpFile)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unix
+
+package testplugin
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/junit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTestCmdReportsHungTests(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module testmod\n\ngo 1.21\n"), 0644))
+	pkgDir := filepath.Join(tmpDir, "hang")
+	require.NoError(t, os.MkdirAll(pkgDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(pkgDir, "hang_test.go"), []byte(`package hang
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPass(t *testing.T) {}
+
+func TestHang(t *testing.T) {
+	time.Sleep(time.Hour)
+}
+`), 0644))
+
+	// the test binary is built before the command is run so that building it does not count towards the timeout
+	buildCmd := goTestCmd(tmpDir, []string{"-run", "^$"}, []string{"./hang"})
+	output, err := buildCmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	artifactsDir := filepath.Join(tmpDir, "artifacts")
+	junitOutput := filepath.Join(tmpDir, "junit.xml")
+	param := TestParam{
+		InactivityTimeout: 2 * time.Second,
+		ArtifactsDir:      artifactsDir,
+	}
+	var stdout bytes.Buffer
+	err = RunTestCmd(tmpDir, nil, nil, junitOutput, nil, param, &stdout)
+	dumpFile := filepath.Join(artifactsDir, "testmod_hang.goroutines.txt")
+	assert.EqualError(t, err, "1 package(s) hung (no output for 2s):\n\ttestmod/hang\n\t\trunning: TestHang\n\t\tgoroutine dump: "+dumpFile)
+	assert.Contains(t, stdout.String(), "No output for 2s: sending SIGQUIT to capture goroutine dumps (running: testmod/hang.TestHang)")
+
+	dump, err := os.ReadFile(dumpFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(dump), "=== goroutine dump written while TestHang was running:\nSIGQUIT: quit")
+	assert.Contains(t, string(dump), "testmod/hang.TestHang(")
+
+	content, err := os.ReadFile(junitOutput)
+	require.NoError(t, err)
+	var suites junit.Testsuites
+	require.NoError(t, xml.Unmarshal(content, &suites))
+	require.Len(t, suites.Suites, 1)
+	var hungTestcase *junit.Testcase
+	for i, testcase := range suites.Suites[0].Testcases {
+		if testcase.Name == "TestHang" {
+			hungTestcase = &suites.Suites[0].Testcases[i]
+		}
+	}
+	require.NotNil(t, hungTestcase)
+	require.NotNil(t, hungTestcase.Error)
+	assert.Equal(t, "hung", hungTestcase.Error.Type)
+	assert.Contains(t, hungTestcase.Error.Data, "testmod/hang.TestHang(")
+}
@@ -0,0 +1,166 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// interruptGracePeriod is the duration for which the test processes are given to exit after a signal that the plugin
+// received is forwarded to them before they are killed.
+const interruptGracePeriod = 10 * time.Second
+
+// interruptHandler handles the signals that request the plugin to stop (SIGINT and SIGTERM) while tests are run. A
+// signal is forwarded to the process groups of all of the running "go test" commands, which are killed if they have not
+// exited after interruptGracePeriod (or immediately if a second signal is received). The running commands can also be
+// stopped by the plugin itself using abort. Once a signal is received or the commands are aborted, no further commands
+// should be started (see stopped).
+type interruptHandler struct {
+	mu        sync.Mutex
+	processes map[*os.Process]struct{}
+	// signal is the first signal that was received, or nil if no signal has been received.
+	signal os.Signal
+	// aborted is true if abort was called.
+	aborted bool
+	// killTimer kills the running processes once the grace period has elapsed.
+	killTimer *time.Timer
+	signals   chan os.Signal
+	done      chan struct{}
+}
+
+// startInterruptHandler starts handling signals. The returned handler must be stopped using stop.
+func startInterruptHandler() *interruptHandler {
+	h := &interruptHandler{
+		processes: make(map[*os.Process]struct{}),
+		signals:   make(chan os.Signal, 1),
+		done:      make(chan struct{}),
+	}
+	signal.Notify(h.signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		for {
+			select {
+			case sig := <-h.signals:
+				h.handle(sig)
+			case <-h.done:
+				return
+			}
+		}
+	}()
+	return h
+}
+
+// stop stops handling signals and restores their default behavior.
+func (h *interruptHandler) stop() {
+	if h == nil {
+		return
+	}
+	signal.Stop(h.signals)
+	close(h.done)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.killTimer != nil {
+		h.killTimer.Stop()
+	}
+}
+
+// handle forwards the provided signal to all of the running processes. The first signal starts the grace period after
+// which the processes are killed, and any subsequent signal kills them immediately.
+func (h *interruptHandler) handle(sig os.Signal) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.signal != nil {
+		h.killLocked()
+		return
+	}
+	h.signal = sig
+	for process := range h.processes {
+		_ = signalProcessGroup(process, sig)
+	}
+	h.killTimer = time.AfterFunc(interruptGracePeriod, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.killLocked()
+	})
+}
+
+func (h *interruptHandler) killLocked() {
+	for process := range h.processes {
+		_ = killProcessGroup(process)
+	}
+}
+
+// abort kills all of the running processes and any processes that are added later. Safe to call on a nil handler.
+func (h *interruptHandler) abort() {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.aborted = true
+	h.killLocked()
+}
+
+// stopped returns true if a signal has been received or abort has been called, in which case no further commands
+// should be started. Safe to call on a nil handler.
+func (h *interruptHandler) stopped() bool {
+	if h == nil {
+		return false
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.signal != nil || h.aborted
+}
+
+// interrupted returns the signal that was received, or nil if no signal has been received. Safe to call on a nil
+// handler.
+func (h *interruptHandler) interrupted() os.Signal {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.signal
+}
+
+// add registers the provided running process so that signals are forwarded to it. If a signal has already been
+// received or abort has been called, the process is signaled or killed immediately. Safe to call on a nil handler.
+func (h *interruptHandler) add(process *os.Process) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.processes[process] = struct{}{}
+	switch {
+	case h.aborted:
+		_ = killProcessGroup(process)
+	case h.signal != nil:
+		_ = signalProcessGroup(process, h.signal)
+	}
+}
+
+// remove unregisters the provided process once it has exited. Safe to call on a nil handler.
+func (h *interruptHandler) remove(process *os.Process) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.processes, process)
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unix
+
+package testplugin
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/junit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTestCmdInterrupted(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module testmod\n\ngo 1.21\n"), 0644))
+	markerFile := filepath.Join(tmpDir, "started")
+	pkgDir := filepath.Join(tmpDir, "slow")
+	require.NoError(t, os.MkdirAll(pkgDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(pkgDir, "slow_test.go"), []byte(`package slow
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestPass(t *testing.T) {}
+
+func TestSlow(t *testing.T) {
+	if err := os.WriteFile(`+"`"+markerFile+"`"+`, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Hour)
+}
+`), 0644))
+
+	junitOutput := filepath.Join(tmpDir, "junit.xml")
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- RunTestCmd(tmpDir, []string{"-count=1"}, nil, junitOutput, nil, TestParam{}, &bytes.Buffer{})
+	}()
+	// the signal is only sent once the test is running, at which point the plugin is handling signals
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(markerFile)
+		return err == nil
+	}, time.Minute, 50*time.Millisecond)
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGINT))
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-time.After(time.Minute):
+		require.Fail(t, "RunTestCmd did not return after it was interrupted")
+	}
+	assert.EqualError(t, err, "tests were interrupted by signal \"interrupt\"\n\ttestmod/slow\n\t\tinterrupted: TestSlow")
+
+	content, err := os.ReadFile(junitOutput)
+	require.NoError(t, err)
+	var suites junit.Testsuites
+	require.NoError(t, xml.Unmarshal(content, &suites))
+	require.Len(t, suites.Suites, 1)
+	testcases := make(map[string]junit.Testcase)
+	for _, testcase := range suites.Suites[0].Testcases {
+		testcases[testcase.Name] = testcase
+	}
+	assert.Nil(t, testcases["TestPass"].Error)
+	assert.Nil(t, testcases["TestPass"].Failure)
+	require.NotNil(t, testcases["TestSlow"].Error)
+	assert.Equal(t, "interrupted", testcases["TestSlow"].Error.Type)
+}
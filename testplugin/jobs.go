@@ -0,0 +1,109 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"bytes"
+	goerrors "errors"
+	"io"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// executeTestCmds executes the commands returned by "nextCmd" until it returns a nil command and records their
+// results in "results". If "jobs" is greater than 1, up to "jobs" commands are executed concurrently: the output of
+// every command is buffered and written to "stdout" once the command completes so that the output of different
+// commands is not interleaved, and the packages in "results" are sorted by name once all of the commands have
+// completed so that the results do not depend on the order in which the commands completed. No further commands are
+// executed once an error that is not an *exec.ExitError occurs, and that error is returned; otherwise, returns the
+// first *exec.ExitError returned by any command (see executeTestCmd).
+func executeTestCmds(nextCmd func() (*exec.Cmd, error), jobs int, stdout io.Writer, results *testResults, opts execOptions) error {
+	if jobs <= 1 {
+		var exitErr error
+		for {
+			cmd, err := nextCmd()
+			if err != nil {
+				return err
+			}
+			if cmd == nil {
+				return exitErr
+			}
+			if err := executeTestCmd(cmd, stdout, results, opts); err != nil {
+				if _, ok := goerrors.AsType[*exec.ExitError](err); !ok {
+					return err
+				}
+				if exitErr == nil {
+					exitErr = err
+				}
+			}
+		}
+	}
+
+	var (
+		// mu guards all of the variables below as well as "nextCmd", "results" and "stdout"
+		mu       sync.Mutex
+		exitErr  error
+		fatalErr error
+		wg       sync.WaitGroup
+	)
+	for range jobs {
+		wg.Go(func() {
+			for {
+				mu.Lock()
+				if fatalErr != nil {
+					mu.Unlock()
+					return
+				}
+				cmd, err := nextCmd()
+				if err != nil {
+					fatalErr = err
+				}
+				mu.Unlock()
+				if cmd == nil || err != nil {
+					return
+				}
+
+				var output bytes.Buffer
+				cmdResults := newTestResults()
+				err = executeTestCmd(cmd, &output, cmdResults, opts)
+
+				mu.Lock()
+				results.merge(cmdResults)
+				_, _ = stdout.Write(output.Bytes())
+				if err != nil {
+					if _, ok := goerrors.AsType[*exec.ExitError](err); !ok {
+						if fatalErr == nil {
+							fatalErr = err
+						}
+					} else if exitErr == nil {
+						exitErr = err
+					}
+				}
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	slices.SortStableFunc(results.pkgs, func(a, b *pkgResult) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	if fatalErr != nil {
+		return fatalErr
+	}
+	return exitErr
+}
@@ -0,0 +1,65 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/jstemmer/go-junit-report/v2/junit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTestCmdJobs(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module testmod\n\ngo 1.21\n"), 0644))
+	for pkg, content := range map[string]string{
+		"a":          "package a\n\nimport \"testing\"\n\nfunc TestPass(t *testing.T) {}\n",
+		"b":          "package b\n\nimport \"testing\"\n\nfunc TestPass(t *testing.T) {}\n",
+		"longername": "package longername\n\nimport \"testing\"\n\nfunc TestPass(t *testing.T) {}\n",
+		"fail":       "package fail\n\nimport \"testing\"\n\nfunc TestFail(t *testing.T) { t.Fatal(\"boom\") }\n",
+		"bad":        "package bad\n\nimport \"testing\"\n\nfunc TestBad(t *testing.T) { undefined() }\n",
+	} {
+		pkgDir := filepath.Join(tmpDir, pkg)
+		require.NoError(t, os.MkdirAll(pkgDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(pkgDir, pkg+"_test.go"), []byte(content), 0644))
+	}
+
+	var stdout bytes.Buffer
+	junitOutput := filepath.Join(tmpDir, "junit.xml")
+	err := RunTestCmd(tmpDir, []string{"-count=1"}, nil, junitOutput, nil, TestParam{Jobs: 3}, &stdout)
+	assert.EqualError(t, err, "1 package(s) failed to build:\n\ttestmod/bad\n\t\tbad/bad_test.go:5:30: undefined: undefined\n1 package(s) had failing tests:\n\ttestmod/fail\n\t\ttestmod/fail.TestFail: fail/fail_test.go:5")
+
+	// the summary lines of the packages, which are tested by different processes, are aligned
+	for _, pkg := range []string{"a", "b"} {
+		assert.Regexp(t, regexp.MustCompile(`(?m)^ok  \ttestmod/`+pkg+` {9}\t`), stdout.String())
+	}
+	assert.Regexp(t, regexp.MustCompile(`(?m)^FAIL\ttestmod/fail {6}\t`), stdout.String())
+
+	content, err := os.ReadFile(junitOutput)
+	require.NoError(t, err)
+	var suites junit.Testsuites
+	require.NoError(t, xml.Unmarshal(content, &suites))
+	var suiteNames []string
+	for _, suite := range suites.Suites {
+		suiteNames = append(suiteNames, suite.Name)
+	}
+	assert.ElementsMatch(t, []string{"testmod/a", "testmod/b", "testmod/bad", "testmod/fail", "testmod/longername"}, suiteNames)
+}
@@ -0,0 +1,269 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// jsonResultsVersion is the version of the format of the JSON output. It is incremented whenever a change to the
+// format is not backwards compatible: fields may be added without changing the version, but fields are never removed
+// or changed.
+const jsonResultsVersion = 1
+
+// The statuses of packages and tests in the JSON output in addition to "pass", "fail" and "skip".
+const (
+	jsonStatusHung        = "hung"
+	jsonStatusInterrupted = "interrupted"
+	jsonStatusNotTested   = "notTested"
+)
+
+// The categories of failures in the JSON output in addition to the kinds of build failures ("build", "vet" and
+// "setup") and crashes ("panic" and "race").
+const (
+	jsonFailureTest        = "test"
+	jsonFailureHung        = "hung"
+	jsonFailureInterrupted = "interrupted"
+)
+
+// jsonResults is the content of the JSON output, which describes a single run of the "test" task. The format is
+// documented in the README and is stable for a given Version.
+type jsonResults struct {
+	Version int `json:"version"`
+	// Status is "pass" if the run succeeded and "fail" otherwise.
+	Status string    `json:"status"`
+	Start  time.Time `json:"start"`
+	// Elapsed is the wall time of the run in seconds.
+	Elapsed float64 `json:"elapsed"`
+	// Tags are the tags that determined the packages to test. Empty if all packages were tested.
+	Tags []string `json:"tags"`
+	// Partition is the partition that was tested. Null if the packages were not partitioned.
+	Partition *jsonPartition `json:"partition"`
+	// Packages contains the results of the packages in the order in which they were reported, followed by the
+	// packages that were selected but not tested (for example, because of FailFast).
+	Packages []jsonPackage `json:"packages"`
+	// Failures contains the failures of the run in the order of the packages.
+	Failures []jsonFailure `json:"failures"`
+}
+
+type jsonPartition struct {
+	Index int `json:"index"`
+	Total int `json:"total"`
+}
+
+type jsonPackage struct {
+	// Name is the import path of the package.
+	Name string `json:"name"`
+	// Status is one of "pass", "fail", "skip", "hung", "interrupted" or "notTested".
+	Status string `json:"status"`
+	// Cached is true if the result of the package was cached by "go test".
+	Cached  bool    `json:"cached"`
+	Elapsed float64 `json:"elapsed"`
+	// Output is the output of the package that was not attributed to any test.
+	Output string     `json:"output"`
+	Tests  []jsonTest `json:"tests"`
+}
+
+type jsonTest struct {
+	// Name is the full name of the test, including the names of parent tests for subtests.
+	Name string `json:"name"`
+	// Status is one of "pass", "fail", "skip", "hung" or "interrupted".
+	Status  string  `json:"status"`
+	Elapsed float64 `json:"elapsed"`
+	// Flaky is true if the test failed and then passed when it was retried.
+	Flaky  bool   `json:"flaky"`
+	Output string `json:"output"`
+}
+
+type jsonFailure struct {
+	Package string `json:"package"`
+	// Test is the full name of the test that failed. Empty if the failure is not attributed to a specific test.
+	Test string `json:"test"`
+	// Category is one of "test", "panic", "race", "build", "vet", "setup", "hung" or "interrupted".
+	Category string `json:"category"`
+	// Location is the location of the failure relative to the project directory (for example, "foo/foo_test.go:12").
+	// Empty if it is not known.
+	Location string `json:"location"`
+	// Message is the first line of the failure message for test failures and crashes, or the output of the compiler
+	// or "go vet" for build failures. Empty if it is not known.
+	Message string `json:"message"`
+}
+
+// writeJSONResults writes the JSON output for the provided results to the provided file. The packages in "pkgs" (in
+// the format returned by PkgsToTest) that do not have results are reported as not tested.
+func writeJSONResults(file, projectDir string, tags []string, partition *Partition, pkgs []string, results *testResults, failed bool, start time.Time) error {
+	out, err := newJSONResults(projectDir, tags, partition, pkgs, results, failed, start)
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal JSON output")
+	}
+	if err := os.WriteFile(file, append(content, '\n'), 0644); err != nil {
+		return errors.Wrapf(err, "failed to write JSON output file")
+	}
+	return nil
+}
+
+func newJSONResults(projectDir string, tags []string, partition *Partition, pkgs []string, results *testResults, failed bool, start time.Time) (jsonResults, error) {
+	out := jsonResults{
+		Version:  jsonResultsVersion,
+		Status:   actionPass,
+		Start:    start,
+		Elapsed:  time.Since(start).Seconds(),
+		Tags:     append([]string{}, tags...),
+		Packages: []jsonPackage{},
+		Failures: []jsonFailure{},
+	}
+	if failed {
+		out.Status = actionFail
+	}
+	if partition != nil {
+		out.Partition = &jsonPartition{Index: partition.Index, Total: partition.Total}
+	}
+
+	failedPkgs := make(map[string]struct{})
+	for _, pkgName := range results.failedPkgs() {
+		failedPkgs[pkgName] = struct{}{}
+	}
+	locator := newFailureLocator(projectDir)
+	for _, pkg := range results.pkgs {
+		_, pkgFailed := failedPkgs[pkg.Name]
+		out.Packages = append(out.Packages, newJSONPackage(pkg, pkgFailed))
+		out.Failures = append(out.Failures, jsonFailures(locator, pkg, pkgFailed)...)
+	}
+
+	if len(pkgs) > 0 {
+		modPath, err := modulePath(projectDir)
+		if err != nil {
+			return jsonResults{}, err
+		}
+		for _, pkg := range pkgs {
+			importPath := importPkgPath(modPath, pkg)
+			if _, ok := results.byName[importPath]; !ok {
+				out.Packages = append(out.Packages, jsonPackage{
+					Name:   importPath,
+					Status: jsonStatusNotTested,
+					Tests:  []jsonTest{},
+				})
+			}
+		}
+	}
+	return out, nil
+}
+
+func newJSONPackage(pkg *pkgResult, failed bool) jsonPackage {
+	out := jsonPackage{
+		Name:    pkg.Name,
+		Status:  pkg.Status,
+		Cached:  pkg.cached(),
+		Elapsed: pkg.Elapsed.Seconds(),
+		Output:  strings.Join(pkg.Output, "\n"),
+		Tests:   []jsonTest{},
+	}
+	switch {
+	case pkg.Hung:
+		out.Status = jsonStatusHung
+	case pkg.Interrupted:
+		out.Status = jsonStatusInterrupted
+	case failed:
+		out.Status = actionFail
+	}
+	for _, test := range pkg.Tests {
+		status := test.Status
+		switch {
+		case test.Hung:
+			status = jsonStatusHung
+		case test.Interrupted:
+			status = jsonStatusInterrupted
+		case status == "":
+			// the test binary exited before the test completed (for example, because another test panicked)
+			status = actionFail
+		}
+		out.Tests = append(out.Tests, jsonTest{
+			Name:    test.Name,
+			Status:  status,
+			Elapsed: test.Elapsed.Seconds(),
+			Flaky:   test.isFlaky(),
+			Output:  strings.Join(test.Output, "\n"),
+		})
+	}
+	return out
+}
+
+// jsonFailures returns the failures of the provided package, which failed if "failed" is true.
+func jsonFailures(locator failureLocator, pkg *pkgResult, failed bool) []jsonFailure {
+	var failures []jsonFailure
+	addFailure := func(test, category, location, message string) {
+		failures = append(failures, jsonFailure{
+			Package:  pkg.Name,
+			Test:     test,
+			Category: category,
+			Location: location,
+			Message:  message,
+		})
+	}
+	switch {
+	case pkg.Hung:
+		hungTests := pkg.hungTests()
+		for _, test := range hungTests {
+			addFailure(test, jsonFailureHung, "", "")
+		}
+		if len(hungTests) == 0 {
+			addFailure("", jsonFailureHung, "", "")
+		}
+		return failures
+	case pkg.buildFailure() != "":
+		var output []string
+		for _, line := range pkg.BuildOutput {
+			if !strings.HasPrefix(line, "# ") {
+				output = append(output, line)
+			}
+		}
+		addFailure("", pkg.buildFailure(), "", strings.Join(output, "\n"))
+		return failures
+	}
+
+	if failed || pkg.Interrupted {
+		testFailures := locator.testFailures(pkg)
+		for _, failure := range testFailures {
+			category := jsonFailureTest
+			if failure.Crash != "" {
+				category = failure.Crash
+			}
+			addFailure(failure.Test, category, failure.Location, failure.Message)
+		}
+		if failed && len(testFailures) == 0 {
+			// the package failed without any failing tests (for example, because TestMain exited with a non-zero code)
+			addFailure("", jsonFailureTest, "", "")
+		}
+	}
+	if pkg.Interrupted {
+		interruptedTests := pkg.interruptedTests()
+		for _, test := range interruptedTests {
+			addFailure(test, jsonFailureInterrupted, "", "")
+		}
+		if len(interruptedTests) == 0 {
+			addFailure("", jsonFailureInterrupted, "", "")
+		}
+	}
+	return failures
+}
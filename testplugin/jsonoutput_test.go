@@ -0,0 +1,108 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTestCmdJSONOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module testmod\n\ngo 1.21\n"), 0644))
+	for pkg, content := range map[string]string{
+		"pass": "package pass\n\nimport \"testing\"\n\nfunc TestPass(t *testing.T) {\n\tt.Log(\"hello\")\n}\n",
+		"fail": "package fail\n\nimport \"testing\"\n\nfunc TestFail(t *testing.T) {\n\tt.Run(\"sub\", func(t *testing.T) {\n\t\tt.Error(\"boom\")\n\t})\n}\n",
+		"bad":  "package bad\n\nimport \"testing\"\n\nfunc TestBad(t *testing.T) { undefined() }\n",
+	} {
+		pkgDir := filepath.Join(tmpDir, pkg)
+		require.NoError(t, os.MkdirAll(pkgDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(pkgDir, pkg+"_test.go"), []byte(content), 0644))
+	}
+
+	var stdout bytes.Buffer
+	jsonOutput := filepath.Join(tmpDir, "results.json")
+	err := RunTestCmd(tmpDir, []string{"-count=1"}, nil, "", &Partition{Index: 0, Total: 1}, TestParam{JSONOutput: jsonOutput}, &stdout)
+	require.Error(t, err)
+
+	content, err := os.ReadFile(jsonOutput)
+	require.NoError(t, err)
+	var results jsonResults
+	require.NoError(t, json.Unmarshal(content, &results))
+
+	assert.Equal(t, jsonResultsVersion, results.Version)
+	assert.Equal(t, "fail", results.Status)
+	assert.Equal(t, []string{}, results.Tags)
+	assert.Equal(t, &jsonPartition{Index: 0, Total: 1}, results.Partition)
+
+	statuses := make(map[string]string)
+	tests := make(map[string]jsonTest)
+	for _, pkg := range results.Packages {
+		statuses[pkg.Name] = pkg.Status
+		for _, test := range pkg.Tests {
+			tests[pkg.Name+"."+test.Name] = test
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"testmod/bad":  "fail",
+		"testmod/fail": "fail",
+		"testmod/pass": "pass",
+	}, statuses)
+	assert.Equal(t, "pass", tests["testmod/pass.TestPass"].Status)
+	assert.Contains(t, tests["testmod/pass.TestPass"].Output, "hello")
+	assert.Equal(t, "fail", tests["testmod/fail.TestFail/sub"].Status)
+
+	assert.ElementsMatch(t, []jsonFailure{
+		{
+			Package:  "testmod/bad",
+			Category: "build",
+			Message:  "bad/bad_test.go:5:30: undefined: undefined",
+		},
+		{
+			Package:  "testmod/fail",
+			Test:     "TestFail/sub",
+			Category: "test",
+			Location: "fail/fail_test.go:7",
+			Message:  "boom",
+		},
+	}, results.Failures)
+}
+
+func TestJSONOutputReportsPackagesThatWereNotTested(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module testmod\n\ngo 1.21\n"), 0644))
+
+	results := newTestResults()
+	results.process(testEvent{Action: actionStart, Package: "testmod"})
+	results.process(testEvent{Action: actionPass, Package: "testmod"})
+
+	out, err := newJSONResults(tmpDir, []string{"unit"}, nil, []string{"./.", "./foo"}, results, false, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, "pass", out.Status)
+	assert.Equal(t, []string{"unit"}, out.Tags)
+	assert.Nil(t, out.Partition)
+	assert.Equal(t, []jsonPackage{
+		{Name: "testmod", Status: "pass", Tests: []jsonTest{}},
+		{Name: "testmod/foo", Status: "notTested", Tests: []jsonTest{}},
+	}, out.Packages)
+	assert.Empty(t, out.Failures)
+}
@@ -19,55 +19,255 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/jstemmer/go-junit-report/v2/gtr"
 	"github.com/jstemmer/go-junit-report/v2/junit"
-	"github.com/jstemmer/go-junit-report/v2/parser/gotest"
 	"github.com/pkg/errors"
 )
 
-// provided an output file, returns a pipe writer to which 'go test' output should be written
-// and a function that should be deferred until after the 'go test' command has completed which
-// flushes and closes the unit output file.
-func startJUnitReporter(junitOutput string) (writer io.Writer, deferFunc func(), err error) {
+// startJUnitReporter creates the provided JUnit output file and returns a function that should be deferred until after
+// the 'go test' command has completed which writes the JUnit report for the provided results to the file and closes
+// it.
+func startJUnitReporter(junitOutput string, results *testResults) (deferFunc func(), err error) {
 	junitOutputFile, err := os.Create(junitOutput)
 	if err != nil {
-		return nil, nil, errors.Wrapf(err, "failed to create JUnit output file")
+		return nil, errors.Wrapf(err, "failed to create JUnit output file")
 	}
 
-	junitInputPipeReader, junitInputPipeWriter := io.Pipe()
-	done := make(chan error)
-	go func() {
-		defer close(done)
+	finish := func() {
+		if err := writeJUnitReport(junitOutputFile, results); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "JUnit reporter failed: %v\n", err)
+		}
+		if err := junitOutputFile.Close(); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Failed to close JUnit reporter output file: %v\n", err)
+		}
+	}
+	return finish, nil
+}
+
+func writeJUnitReport(w io.Writer, results *testResults) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("error writing xml header: %w", err)
+	}
+	testsuites := junitTestsuites(results)
+	if err := testsuites.WriteXML(w); err != nil {
+		return fmt.Errorf("error writing xml testsuites: %w", err)
+	}
+	return nil
+}
 
-		report, err := gotest.NewParser().Parse(junitInputPipeReader)
-		if err != nil {
-			done <- fmt.Errorf("error parsing input: %w", err)
-			return
+// junitBuildFailureMessages contains the message of the error that represents every kind of build failure.
+var junitBuildFailureMessages = map[string]string{
+	buildFailureCompile: "Build error",
+	buildFailureVet:     "Vet error",
+	buildFailureSetup:   "Setup error",
+}
+
+// junitTestsuites returns the JUnit representation of the provided results. Every package is a testsuite and every
+// test is a testcase. JUnit does not have a way to represent failures that occur outside of a test, so a package that
+// failed to build or that failed without any failing tests is represented by a single testcase with an error that
+// contains the output of the package. The errors of packages that failed to build have the type "build", "vet" or
+// "setup" depending on the kind of failure and contain the output of the compiler or "go vet". Tests and packages that
+// panicked or had data races are represented by failures of type "panic" or "race" that contain the panics and data
+// race reports. Tests that hung are represented by testcases with errors of type "hung" that contain their goroutine
+// dumps, as is a package that hung while no test was running. Similarly, tests and packages that had not completed
+// when the run was interrupted by a signal are represented by errors of type "interrupted".
+func junitTestsuites(results *testResults) junit.Testsuites {
+	var suites junit.Testsuites
+	for _, pkg := range results.pkgs {
+		suite := junit.Testsuite{
+			Name: pkg.Name,
+			ID:   len(suites.Suites),
+			Time: junitDuration(pkg.Elapsed),
 		}
-		if _, err := io.WriteString(junitOutputFile, xml.Header); err != nil {
-			done <- fmt.Errorf("error writing xml header: %w", err)
-			return
+		if !pkg.Start.IsZero() {
+			suite.SetTimestamp(pkg.Start)
 		}
-		testsuites := junit.CreateFromReport(report, "")
-		if err := testsuites.WriteXML(junitOutputFile); err != nil {
-			done <- fmt.Errorf("error writing xml testsuites: %w", err)
-			return
+		if output := junitOutputData(pkg.Output); output != "" {
+			suite.SystemOut = &junit.Output{Data: output}
 		}
-	}()
 
-	finish := func() {
-		// Close the reporter input to signal it should finish the report
-		if err := junitInputPipeWriter.Close(); err != nil {
-			_, _ = fmt.Fprintf(os.Stderr, "Failed to close JUnit reporter input reader: %v\n", err)
+		crashes := pkg.crashes()
+		failedTests, hungTests, interruptedTests := false, false, false
+		for _, test := range pkg.Tests {
+			failedTests = failedTests || test.Status == actionFail
+			hungTests = hungTests || test.Hung
+			interruptedTests = interruptedTests || test.Interrupted
+			suite.AddTestcase(junitTestcase(pkg.Name, test, crashes[test.Name]))
 		}
-		// Blocks until the reporter has finished writing its output
-		if err := <-done; err != nil {
-			_, _ = fmt.Fprintf(os.Stderr, "JUnit reporter failed: %v\n", err)
+
+		switch {
+		case pkg.Interrupted && !interruptedTests:
+			suite.AddTestcase(junit.Testcase{
+				Classname: pkg.Name,
+				Name:      "[interrupted]",
+				Time:      junitDuration(0),
+				Error: &junit.Result{
+					Message: "Package interrupted",
+					Type:    "interrupted",
+					Data:    junitOutputData(pkg.Output),
+				},
+			})
+		case pkg.Hung && !hungTests:
+			suite.AddTestcase(junit.Testcase{
+				Classname: pkg.Name,
+				Name:      "[hung]",
+				Time:      junitDuration(0),
+				Error: &junit.Result{
+					Message: "Package hung",
+					Type:    "hung",
+					Data:    junitOutputData(append(slices.Clone(pkg.Output), pkg.GoroutineDump...)),
+				},
+			})
+		case pkg.buildFailure() != "":
+			kind := pkg.buildFailure()
+			suite.AddTestcase(junit.Testcase{
+				Classname: pkg.Name,
+				Name:      "[" + kind + " failed]",
+				Time:      junitDuration(0),
+				Error: &junit.Result{
+					Message: junitBuildFailureMessages[kind],
+					Type:    kind,
+					Data:    junitOutputData(pkg.BuildOutput),
+				},
+			})
+		case pkg.Status == actionFail && len(crashes[""]) > 0:
+			suite.AddTestcase(junit.Testcase{
+				Classname: pkg.Name,
+				Name:      "Failure",
+				Time:      junitDuration(0),
+				Failure:   junitCrashResult(crashes[""]),
+			})
+		case pkg.Status == actionFail && !failedTests:
+			suite.AddTestcase(junit.Testcase{
+				Classname: pkg.Name,
+				Name:      "Failure",
+				Time:      junitDuration(0),
+				Error: &junit.Result{
+					Message: "Runtime error",
+					Data:    junitOutputData(pkg.Output),
+				},
+			})
 		}
-		if err := junitOutputFile.Close(); err != nil {
-			_, _ = fmt.Fprintf(os.Stderr, "Failed to close JUnit reporter output file: %v\n", err)
+		suites.AddSuite(suite)
+	}
+	return suites
+}
+
+// junitCrashResult returns the failure that represents the provided crashes: its message is the message of the first
+// panic (or, if there was no panic, of the first data race), its type is the kind of that crash and its data contains
+// the output of all of the crashes, including the full stack traces.
+func junitCrashResult(crashes []crash) *junit.Result {
+	first := primaryCrash(crashes)
+	var output []string
+	for i, c := range crashes {
+		if i > 0 {
+			output = append(output, "")
 		}
+		output = append(output, c.Output...)
+	}
+	return &junit.Result{
+		Message: first.Message(),
+		Type:    first.Kind,
+		Data:    junitOutputData(output),
 	}
+}
+
+func junitTestcase(pkgName string, test *testResult, crashes []crash) junit.Testcase {
+	tc := junit.Testcase{
+		Classname: pkgName,
+		Name:      test.Name,
+		Time:      junitDuration(test.Elapsed),
+	}
+	output := junitOutputData(testOutputLines(test.Name, test.Output))
+	if test.Hung {
+		tc.Error = &junit.Result{
+			Message: "Test hung",
+			Type:    "hung",
+			Data:    junitOutputData(append(testOutputLines(test.Name, test.Output), test.GoroutineDump...)),
+		}
+		return tc
+	}
+	if test.Interrupted {
+		tc.Error = &junit.Result{
+			Message: "Test interrupted",
+			Type:    "interrupted",
+			Data:    output,
+		}
+		return tc
+	}
+	if len(crashes) > 0 && test.Status != actionPass && test.Status != actionSkip {
+		tc.Failure = junitCrashResult(crashes)
+		return tc
+	}
+	switch test.Status {
+	case actionFail:
+		tc.Failure = &junit.Result{
+			Message: "Failed",
+			Data:    output,
+		}
+	case actionSkip:
+		tc.Skipped = &junit.Result{
+			Message: "Skipped",
+			Data:    output,
+		}
+	case actionPass:
+		if test.isFlaky() {
+			// a flaky test passes, but the output of the attempts that failed is retained so that the failures can
+			// be investigated
+			tc.Status = "flaky"
+			var attempts []string
+			for i, attempt := range test.FailedAttempts {
+				attempts = append(attempts, fmt.Sprintf("Attempt %d failed:", i+1))
+				attempts = append(attempts, testOutputLines(test.Name, attempt)...)
+			}
+			attempts = append(attempts, fmt.Sprintf("Attempt %d passed:", len(test.FailedAttempts)+1))
+			output = junitOutputData(append(attempts, testOutputLines(test.Name, test.Output)...))
+		}
+		if output != "" {
+			tc.SystemOut = &junit.Output{Data: output}
+		}
+	default:
+		tc.Error = &junit.Result{
+			Message: "No test result found",
+			Data:    output,
+		}
+	}
+	return tc
+}
 
-	return junitInputPipeWriter, finish, nil
+// testOutputLines returns the provided output of the test with the provided name without the lines generated by the
+// testing framework and without the indentation that the testing framework adds to the output of the test.
+func testOutputLines(testName string, output []string) []string {
+	level := strings.Count(testName, "/")
+	var lines []string
+	for _, line := range output {
+		if isFrameLine(line) {
+			continue
+		}
+		lines = append(lines, gtr.TrimPrefixSpaces(line, level))
+	}
+	return lines
+}
+
+// junitDuration returns the JUnit string representation of the provided duration.
+func junitDuration(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// junitOutputData combines the provided lines into a single string, replacing any characters that are not legal in
+// XML.
+func junitOutputData(lines []string) string {
+	return strings.Map(func(r rune) rune {
+		if r == 0x09 || r == 0x0A || r == 0x0D ||
+			r >= 0x20 && r <= 0xD7FF ||
+			r >= 0xE000 && r <= 0xFFFD ||
+			r >= 0x10000 && r <= 0x10FFFF {
+			return r
+		}
+		return '\uFFFD'
+	}, strings.Join(lines, "\n"))
 }
@@ -0,0 +1,98 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jstemmer/go-junit-report/v2/junit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJUnitTestsuites(t *testing.T) {
+	const events = `{"ImportPath":"testmod/bad [testmod/bad.test]","Action":"build-output","Output":"# testmod/bad [testmod/bad.test]\n"}
+{"ImportPath":"testmod/bad [testmod/bad.test]","Action":"build-output","Output":"bad/bad.go:3:9: undefined: undefined\n"}
+{"ImportPath":"testmod/bad [testmod/bad.test]","Action":"build-fail"}
+{"Action":"start","Package":"testmod/bad"}
+{"Action":"output","Package":"testmod/bad","Output":"FAIL\ttestmod/bad [build failed]\n"}
+{"Action":"fail","Package":"testmod/bad","Elapsed":0,"FailedBuild":"testmod/bad [testmod/bad.test]"}
+{"Action":"start","Package":"testmod/foo"}
+{"Action":"run","Package":"testmod/foo","Test":"TestFoo"}
+{"Action":"output","Package":"testmod/foo","Test":"TestFoo","Output":"=== RUN   TestFoo\n"}
+{"Action":"run","Package":"testmod/foo","Test":"TestFoo/bar"}
+{"Action":"output","Package":"testmod/foo","Test":"TestFoo/bar","Output":"=== RUN   TestFoo/bar\n"}
+{"Action":"output","Package":"testmod/foo","Test":"TestFoo/bar","Output":"        foo_test.go:13: assertion failed\n"}
+{"Action":"output","Package":"testmod/foo","Test":"TestFoo/bar","Output":"    --- FAIL: TestFoo/bar (1.50s)\n"}
+{"Action":"fail","Package":"testmod/foo","Test":"TestFoo/bar","Elapsed":1.5}
+{"Action":"output","Package":"testmod/foo","Test":"TestFoo","Output":"--- FAIL: TestFoo (1.50s)\n"}
+{"Action":"fail","Package":"testmod/foo","Test":"TestFoo","Elapsed":1.5}
+{"Action":"run","Package":"testmod/foo","Test":"TestSkipped"}
+{"Action":"output","Package":"testmod/foo","Test":"TestSkipped","Output":"    foo_test.go:20: not supported\n"}
+{"Action":"skip","Package":"testmod/foo","Test":"TestSkipped","Elapsed":0}
+{"Action":"output","Package":"testmod/foo","Output":"FAIL\n"}
+{"Action":"output","Package":"testmod/foo","Output":"FAIL\ttestmod/foo\t1.502s\n"}
+{"Action":"fail","Package":"testmod/foo","Elapsed":1.502}
+`
+	results := newTestResults()
+	for line := range strings.Lines(events) {
+		ev, ok := parseTestEvent(line)
+		require.True(t, ok, "failed to parse %q", line)
+		results.process(ev)
+	}
+
+	suites := junitTestsuites(results)
+	require.Len(t, suites.Suites, 2)
+	assert.Equal(t, 4, suites.Tests)
+	assert.Equal(t, 1, suites.Errors)
+	assert.Equal(t, 2, suites.Failures)
+	assert.Equal(t, 1, suites.Skipped)
+
+	assert.Equal(t, []junit.Testcase{
+		{
+			Name:      "[build failed]",
+			Classname: "testmod/bad",
+			Time:      "0.000",
+			Error: &junit.Result{
+				Message: "Build error",
+				Type:    "build",
+				Data:    "# testmod/bad [testmod/bad.test]\nbad/bad.go:3:9: undefined: undefined",
+			},
+		},
+	}, suites.Suites[0].Testcases)
+
+	assert.Equal(t, "1.502", suites.Suites[1].Time)
+	assert.Equal(t, []junit.Testcase{
+		{
+			Name:      "TestFoo",
+			Classname: "testmod/foo",
+			Time:      "1.500",
+			Failure:   &junit.Result{Message: "Failed"},
+		},
+		{
+			Name:      "TestFoo/bar",
+			Classname: "testmod/foo",
+			Time:      "1.500",
+			Failure:   &junit.Result{Message: "Failed", Data: "foo_test.go:13: assertion failed"},
+		},
+		{
+			Name:      "TestSkipped",
+			Classname: "testmod/foo",
+			Time:      "0.000",
+			Skipped:   &junit.Result{Message: "Skipped", Data: "foo_test.go:20: not supported"},
+		},
+	}, suites.Suites[1].Testcases)
+}
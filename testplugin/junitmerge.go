@@ -0,0 +1,174 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/junit"
+	"github.com/pkg/errors"
+)
+
+// MergeJUnitReports merges the provided JUnit reports (for example, the reports written by the partitions of a run)
+// into a single report that is written to the output file. The testsuites with the same name are combined into a
+// single testsuite, so a package whose tests were split across partitions is reported as one testsuite that contains
+// the testcases of all of the partitions: its counts and times are the sums of the counts and times of the testsuites,
+// its timestamp is the earliest timestamp and its output is the output of all of the testsuites. The testsuites are
+// ordered by their first occurrence in the reports.
+func MergeJUnitReports(output string, inputs []string, stdout io.Writer) error {
+	if len(inputs) == 0 {
+		return errors.Errorf("no JUnit reports to merge")
+	}
+	var reports []junit.Testsuites
+	for _, input := range inputs {
+		content, err := os.ReadFile(input)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read JUnit report")
+		}
+		var report junit.Testsuites
+		if err := xml.Unmarshal(content, &report); err != nil {
+			// a report may consist of a single testsuite
+			var suite junit.Testsuite
+			if suiteErr := xml.Unmarshal(content, &suite); suiteErr != nil {
+				return errors.Wrapf(err, "failed to unmarshal JUnit report %s", input)
+			}
+			report.Suites = []junit.Testsuite{suite}
+		}
+		reports = append(reports, report)
+	}
+	merged, err := mergeJUnitTestsuites(reports)
+	if err != nil {
+		return err
+	}
+
+	outputFile, err := os.Create(output)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create JUnit output file")
+	}
+	if _, err := io.WriteString(outputFile, xml.Header); err != nil {
+		_ = outputFile.Close()
+		return errors.Wrapf(err, "failed to write JUnit report")
+	}
+	if err := merged.WriteXML(outputFile); err != nil {
+		_ = outputFile.Close()
+		return errors.Wrapf(err, "failed to write JUnit report")
+	}
+	if err := outputFile.Close(); err != nil {
+		return errors.Wrapf(err, "failed to close JUnit output file")
+	}
+	_, _ = fmt.Fprintf(stdout, "Merged %d JUnit report(s) (%d testsuite(s), %d testcase(s)) written to %s\n", len(inputs), len(merged.Suites), merged.Tests, output)
+	return nil
+}
+
+// mergeJUnitTestsuites combines the testsuites with the same name in the provided reports (see MergeJUnitReports).
+func mergeJUnitTestsuites(reports []junit.Testsuites) (junit.Testsuites, error) {
+	var suites []*junit.Testsuite
+	suitesByName := make(map[string]*junit.Testsuite)
+	for _, report := range reports {
+		for _, suite := range report.Suites {
+			merged, ok := suitesByName[suite.Name]
+			if !ok {
+				merged = &junit.Testsuite{
+					Name:       suite.Name,
+					Hostname:   suite.Hostname,
+					Package:    suite.Package,
+					File:       suite.File,
+					Time:       junitDuration(0),
+					Properties: suite.Properties,
+				}
+				suitesByName[suite.Name] = merged
+				suites = append(suites, merged)
+			}
+			if err := mergeJUnitTestsuite(merged, suite); err != nil {
+				return junit.Testsuites{}, err
+			}
+		}
+	}
+
+	var merged junit.Testsuites
+	var total time.Duration
+	for _, suite := range suites {
+		suite.ID = len(merged.Suites)
+		merged.AddSuite(*suite)
+		elapsed, _ := parseJUnitDuration(suite.Time)
+		total += elapsed
+	}
+	merged.Time = junitDuration(total)
+	return merged, nil
+}
+
+// mergeJUnitTestsuite adds the testcases, counts, time and output of the provided testsuite to the provided merged
+// testsuite.
+func mergeJUnitTestsuite(merged *junit.Testsuite, suite junit.Testsuite) error {
+	merged.Testcases = append(merged.Testcases, suite.Testcases...)
+	merged.Tests += suite.Tests
+	merged.Failures += suite.Failures
+	merged.Errors += suite.Errors
+	merged.Skipped += suite.Skipped
+	merged.Disabled += suite.Disabled
+
+	mergedTime, err := parseJUnitDuration(merged.Time)
+	if err != nil {
+		return err
+	}
+	suiteTime, err := parseJUnitDuration(suite.Time)
+	if err != nil {
+		return errors.Wrapf(err, "invalid time of testsuite %s", suite.Name)
+	}
+	merged.Time = junitDuration(mergedTime + suiteTime)
+
+	if suite.Timestamp != "" {
+		suiteStart, err := time.Parse(time.RFC3339, suite.Timestamp)
+		if err != nil {
+			return errors.Wrapf(err, "invalid timestamp of testsuite %s", suite.Name)
+		}
+		mergedStart, err := time.Parse(time.RFC3339, merged.Timestamp)
+		if merged.Timestamp == "" || err == nil && suiteStart.Before(mergedStart) {
+			merged.Timestamp = suite.Timestamp
+		}
+	}
+	merged.SystemOut = mergeJUnitOutput(merged.SystemOut, suite.SystemOut)
+	merged.SystemErr = mergeJUnitOutput(merged.SystemErr, suite.SystemErr)
+	return nil
+}
+
+// mergeJUnitOutput returns the concatenation of the provided outputs, either of which may be nil.
+func mergeJUnitOutput(a, b *junit.Output) *junit.Output {
+	switch {
+	case b == nil || b.Data == "":
+		return a
+	case a == nil || a.Data == "":
+		return &junit.Output{Data: b.Data}
+	default:
+		return &junit.Output{Data: a.Data + "\n" + b.Data}
+	}
+}
+
+// parseJUnitDuration parses the provided JUnit time in seconds. An empty time is 0.
+func parseJUnitDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	seconds, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid JUnit time %q", s)
+	}
+	return secondsDuration(seconds), nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeJUnitReports(t *testing.T) {
+	tmpDir := t.TempDir()
+	for file, content := range map[string]string{
+		"partition-0.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="1">
+	<testsuite name="testmod/big" tests="2" failures="1" errors="0" id="0" time="1.500" timestamp="2026-01-02T10:00:05Z">
+		<testcase name="TestA" classname="testmod/big" time="0.500"></testcase>
+		<testcase name="TestC" classname="testmod/big" time="1.000">
+			<failure message="Failed"><![CDATA[failed]]></failure>
+		</testcase>
+		<system-out><![CDATA[partition 0]]></system-out>
+	</testsuite>
+	<testsuite name="testmod/a" tests="1" failures="0" errors="0" id="1" time="0.100">
+		<testcase name="TestA" classname="testmod/a" time="0.100"></testcase>
+	</testsuite>
+</testsuites>
+`,
+		// a report may consist of a single testsuite
+		"partition-1.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="testmod/big" tests="2" failures="0" errors="0" skipped="1" id="0" time="2.250" timestamp="2026-01-02T10:00:00Z">
+	<testcase name="TestB" classname="testmod/big" time="2.250"></testcase>
+	<testcase name="TestD" classname="testmod/big" time="0.000">
+		<skipped message="Skipped"></skipped>
+	</testcase>
+	<system-out><![CDATA[partition 1]]></system-out>
+</testsuite>
+`,
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, file), []byte(content), 0644))
+	}
+
+	var stdout bytes.Buffer
+	output := filepath.Join(tmpDir, "junit.xml")
+	err := MergeJUnitReports(output, []string{filepath.Join(tmpDir, "partition-0.xml"), filepath.Join(tmpDir, "partition-1.xml")}, &stdout)
+	require.NoError(t, err)
+	assert.Equal(t, "Merged 2 JUnit report(s) (2 testsuite(s), 5 testcase(s)) written to "+output+"\n", stdout.String())
+
+	content, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites time="3.850" tests="5" failures="1" skipped="1">
+	<testsuite name="testmod/big" tests="4" failures="1" errors="0" id="0" skipped="1" time="3.750" timestamp="2026-01-02T10:00:00Z">
+		<testcase name="TestA" classname="testmod/big" time="0.500"></testcase>
+		<testcase name="TestC" classname="testmod/big" time="1.000">
+			<failure message="Failed"><![CDATA[failed]]></failure>
+		</testcase>
+		<testcase name="TestB" classname="testmod/big" time="2.250"></testcase>
+		<testcase name="TestD" classname="testmod/big" time="0.000">
+			<skipped message="Skipped"></skipped>
+		</testcase>
+		<system-out><![CDATA[partition 0
+partition 1]]></system-out>
+	</testsuite>
+	<testsuite name="testmod/a" tests="1" failures="0" errors="0" id="1" time="0.100">
+		<testcase name="TestA" classname="testmod/a" time="0.100"></testcase>
+	</testsuite>
+</testsuites>
+`, string(content))
+
+	err = MergeJUnitReports(output, nil, &stdout)
+	assert.EqualError(t, err, "no JUnit reports to merge")
+}
@@ -18,6 +18,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/palantir/pkg/matcher"
 	"github.com/pkg/errors"
@@ -36,9 +37,170 @@ type TestParam struct {
 
 	// Exclude specifies the files that should be excluded from tests.
 	Exclude matcher.Matcher
+
+	// Retries is the number of times that the tests that failed are re-run. A test that passes on a retry is reported
+	// as flaky rather than failed.
+	Retries int
+
+	// PartitionStrategy is the strategy used to assign packages to partitions when tests are partitioned. The zero
+	// value is equivalent to PartitionStrategyContiguous.
+	PartitionStrategy PartitionStrategy
+
+	// ConsoleOutput determines the output of the tests that is printed to the console. If empty, the output of all
+	// tests is printed if the "-v" flag is provided to "go test" and only the output of failing tests is printed
+	// otherwise.
+	ConsoleOutput ConsoleOutput
+
+	// Color determines whether the console output is colored. The zero value is equivalent to ColorAuto.
+	Color ColorMode
+
+	// SplitPackageThreshold is the number of top-level tests above which the tests of a package are split across
+	// partitions rather than the whole package being assigned to a single partition. If 0, packages are not split.
+	SplitPackageThreshold int
+
+	// Jobs is the number of "go test" processes that are run concurrently. If greater than 0, every package is tested
+	// by its own "go test" process rather than all of the packages being tested by a single process.
+	Jobs int
+
+	// InactivityTimeout is the duration without any output from "go test" after which the tests are considered to be
+	// hung. The test binaries are then sent SIGQUIT to capture goroutine dumps, and the packages and tests that were
+	// running are reported as hung. If 0, tests are never considered to be hung.
+	InactivityTimeout time.Duration
+
+	// ArtifactsDir is the directory to which the goroutine dumps of hung packages are written. If empty, a temporary
+	// directory is used.
+	ArtifactsDir string
+
+	// QueueDir is a directory shared by multiple nodes from which every node claims the packages to test one at a time
+	// rather than testing a static partition of the packages. If empty, packages are not claimed from a queue.
+	QueueDir string
+
+	// QueueID is the ID of the run that uses the queue in QueueDir, which must be shared by all of the nodes of the run
+	// and unique for every run (for example, the ID of the CI pipeline). Required if QueueDir is specified. A queue
+	// directory that was used by a previous run is reset.
+	QueueID string
+
+	// Progress reports the progress of the run while the packages are tested: if the output is a terminal, a progress
+	// line is kept at the bottom of the output; otherwise, a progress line is printed every ProgressInterval.
+	Progress bool
+
+	// ProgressInterval is the interval at which progress lines are printed if Progress is true and the output is not a
+	// terminal. If 0, DefaultProgressInterval is used.
+	ProgressInterval time.Duration
+
+	// Summary configures the summary of the run that is printed once all of the packages have been tested.
+	Summary SummaryParam
+
+	// Coverage configures the minimum coverage that the tests must achieve.
+	Coverage CoverageParam
+
+	// FailFast stops testing as soon as any package fails: the running "go test" processes are killed and no further
+	// packages are tested. The failed tests are not retried.
+	FailFast bool
+
+	// CoverageOutput is the directory to which the merged coverage profile of all of the tested packages is written
+	// (see CoverageProfileFile). If empty, coverage is not collected.
+	CoverageOutput string
+
+	// CoverageFormats are the formats of the coverage reports that are written to CoverageOutput along with the
+	// merged coverage profile.
+	CoverageFormats []CoverageFormat
+
+	// CoverageBase is the git revision relative to which the coverage of the changed lines is reported (see
+	// changedCoverage). If non-empty, coverage is collected even if CoverageOutput is empty.
+	CoverageBase string
+
+	// JSONOutput is the file to which the results of the run are written in JSON format (see writeJSONResults). If
+	// empty, the results are not written.
+	JSONOutput string
+
+	// TimingsOutput is the file to which the durations of testing the packages are written. The file can be provided
+	// to ReadPartitionTimings to balance the partitions of a later run. If empty, the durations are not written.
+	TimingsOutput string
+}
+
+// DefaultSummarySlowest is the number of slowest packages and tests that are listed in the summary of the run if it is
+// not configured.
+const DefaultSummarySlowest = 5
+
+// SummaryParam configures the summary of the run.
+type SummaryParam struct {
+	// Disabled suppresses the summary.
+	Disabled bool
+
+	// Slowest is the number of slowest packages and tests that are listed in the summary. If 0, none are listed.
+	Slowest int
+}
+
+// CoverageParam configures the minimum percentages of statements that the tests must cover. A minimum of 0 is not
+// enforced. The minimums of the module and of the packages are not enforced if only the packages of some tags are
+// tested.
+type CoverageParam struct {
+	// Min is the minimum percentage of the statements of all of the packages in the module that must be covered.
+	Min float64
+
+	// Packages are the minimum percentages of the statements of the packages that match their matchers that must be
+	// covered. The minimum of every package is the highest minimum of the matchers that match it.
+	Packages []PackageCoverageMin
+
+	// Tags contains the minimum percentage of the statements of the module that must be covered by the tests of the
+	// packages that are part of a tag keyed by tag. The minimums are only checked for the tags that were tested.
+	Tags map[string]float64
+
+	// ChangedMin is the minimum percentage of the executable lines that changed relative to the coverage base that
+	// must be covered. Only enforced if a coverage base is specified (see TestParam.CoverageBase).
+	ChangedMin float64
+}
+
+// PackageCoverageMin is the minimum coverage of the packages that match a matcher. The matcher is matched against the
+// paths of the packages relative to the project directory.
+type PackageCoverageMin struct {
+	Matcher matcher.Matcher
+	Min     float64
+}
+
+// hasMins returns true if any minimum coverage other than ChangedMin is configured.
+func (p CoverageParam) hasMins() bool {
+	return p.Min > 0 || len(p.Packages) > 0 || len(p.Tags) > 0
 }
 
 func (p *TestParam) Validate() error {
+	if p.Retries < 0 {
+		return errors.Errorf("retries must be non-negative, got %d", p.Retries)
+	}
+	if p.InactivityTimeout < 0 {
+		return errors.Errorf("inactivity timeout must be non-negative, got %v", p.InactivityTimeout)
+	}
+	if p.ProgressInterval < 0 {
+		return errors.Errorf("progress interval must be non-negative, got %v", p.ProgressInterval)
+	}
+	if p.Jobs < 0 {
+		return errors.Errorf("jobs must be non-negative, got %d", p.Jobs)
+	}
+	if p.Summary.Slowest < 0 {
+		return errors.Errorf("number of slowest packages and tests in the summary must be non-negative, got %d", p.Summary.Slowest)
+	}
+	if p.SplitPackageThreshold < 0 {
+		return errors.Errorf("split package threshold must be non-negative, got %d", p.SplitPackageThreshold)
+	}
+	if _, err := ParsePartitionStrategy(string(p.PartitionStrategy)); err != nil {
+		return err
+	}
+	if _, err := ParseConsoleOutput(string(p.ConsoleOutput)); err != nil {
+		return err
+	}
+	if _, err := ParseColorMode(string(p.Color)); err != nil {
+		return err
+	}
+	for _, format := range p.CoverageFormats {
+		if _, err := ParseCoverageFormat(string(format)); err != nil {
+			return err
+		}
+	}
+	if len(p.CoverageFormats) > 0 && p.CoverageOutput == "" {
+		return errors.Errorf("coverage formats can only be specified if a coverage output directory is specified")
+	}
+
 	var invalidTagNames []string
 	seenTagNames := make(map[string]struct{})
 	duplicateTagNames := make(map[string]struct{})
@@ -79,9 +241,44 @@ func (p *TestParam) Validate() error {
 		p.Tags[strings.ToLower(k)] = v
 	}
 
+	return p.Coverage.validate(p.Tags)
+}
+
+// validate verifies that the minimums are percentages and that the minimums of tags refer to the provided tags (whose
+// names must be normalized). The names of the tags of the minimums are normalized to all lowercase.
+func (p *CoverageParam) validate(tags map[string]matcher.Matcher) error {
+	if !validCoverageMin(p.Min) {
+		return errors.Errorf("coverage minimum must be between 0 and 100, got %v", p.Min)
+	}
+	if !validCoverageMin(p.ChangedMin) {
+		return errors.Errorf("coverage minimum of changed lines must be between 0 and 100, got %v", p.ChangedMin)
+	}
+	for _, pkgMin := range p.Packages {
+		if !validCoverageMin(pkgMin.Min) {
+			return errors.Errorf("coverage minimum of packages must be between 0 and 100, got %v", pkgMin.Min)
+		}
+	}
+	normalized := make(map[string]float64, len(p.Tags))
+	for tag, minCoverage := range p.Tags {
+		if !validCoverageMin(minCoverage) {
+			return errors.Errorf("coverage minimum of tag %q must be between 0 and 100, got %v", tag, minCoverage)
+		}
+		tag = strings.ToLower(tag)
+		if _, ok := tags[tag]; !ok && tag != AllTagName && tag != NoneTagName {
+			return errors.Errorf("coverage minimum specified for tag %q, which is not defined", tag)
+		}
+		normalized[tag] = minCoverage
+	}
+	if p.Tags != nil {
+		p.Tags = normalized
+	}
 	return nil
 }
 
+func validCoverageMin(minCoverage float64) bool {
+	return minCoverage >= 0 && minCoverage <= 100
+}
+
 var tagRegExp = regexp.MustCompile(`[A-Za-z0-9_-]+`)
 
 func validTagName(tag string) bool {
@@ -15,27 +15,157 @@
 package testplugin
 
 import (
+	"cmp"
 	"fmt"
+	"hash/fnv"
+	"os"
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
+// defaultPackageDuration is the expected duration of testing a package that is used for timing-balanced partitioning
+// if none of the packages have timings.
+const defaultPackageDuration = time.Second
+
+// PartitionStrategy is the strategy used to assign packages to partitions.
+type PartitionStrategy string
+
+const (
+	// PartitionStrategyContiguous splits the sorted packages into contiguous partitions with the same number of
+	// packages. This is the default strategy.
+	PartitionStrategyContiguous PartitionStrategy = "contiguous"
+	// PartitionStrategyHash assigns every package to a partition based on a consistent hash of its path, so adding or
+	// removing a package does not change the partition of any other package.
+	PartitionStrategyHash PartitionStrategy = "hash"
+)
+
+// ParsePartitionStrategy parses the provided partition strategy. Returns PartitionStrategyContiguous if the input is
+// empty.
+func ParsePartitionStrategy(s string) (PartitionStrategy, error) {
+	switch strategy := PartitionStrategy(s); strategy {
+	case "":
+		return PartitionStrategyContiguous, nil
+	case PartitionStrategyContiguous, PartitionStrategyHash:
+		return strategy, nil
+	default:
+		return "", errors.Errorf("invalid partition strategy %q: must be one of %q or %q", s, PartitionStrategyContiguous, PartitionStrategyHash)
+	}
+}
+
 // Partition represents a partition configuration for splitting packages.
 type Partition struct {
 	Index int // 0-indexed partition number
 	Total int // total number of partitions
+
+	// Strategy is the strategy used to assign packages to partitions. The zero value is equivalent to
+	// PartitionStrategyContiguous. Ignored if Timings is non-nil.
+	Strategy PartitionStrategy
+
+	// Timings contains the durations of testing packages in a previous run keyed by package (see
+	// ReadPartitionTimings). If non-nil, the packages are assigned to partitions so that every partition has roughly
+	// the same expected duration rather than the same number of packages.
+	Timings map[string]time.Duration
+
+	// Plan, if non-nil, is a plan that determines the packages of every partition (see ReadTestPlan). If set,
+	// PkgsToTest returns the packages assigned to the partition by the plan rather than partitioning the packages that
+	// match the tags.
+	Plan *TestPlan
+}
+
+const (
+	// AutoPartition is the partition value that detects the partition from environment variables (see ParsePartition).
+	AutoPartition = "auto"
+
+	// PartitionEnvVar is the environment variable that can be set to a partition in the format "X,N" to specify the
+	// partition independently of the CI provider.
+	PartitionEnvVar = "GODEL_TEST_PARTITION"
+)
+
+// partitionEnvVars are the pairs of environment variables set by CI providers to the index and total of the parallel
+// node that a job runs on, in the order in which they are checked.
+var partitionEnvVars = []struct {
+	index, total string
+	// oneIndexed is true if the value of the index variable is 1-indexed.
+	oneIndexed bool
+}{
+	{index: "CIRCLE_NODE_INDEX", total: "CIRCLE_NODE_TOTAL"},
+	{index: "BUILDKITE_PARALLEL_JOB", total: "BUILDKITE_PARALLEL_JOB_COUNT"},
+	{index: "CI_NODE_INDEX", total: "CI_NODE_TOTAL", oneIndexed: true},
 }
 
 // ParsePartition parses a partition string in the format "X,N" where X is the
 // 0-indexed partition number and N is the total number of partitions.
-// Returns nil if the input is empty (no partitioning).
+// If the input is empty or AutoPartition, the partition is detected from the GODEL_TEST_PARTITION environment variable
+// (in the format "X,N") or from the environment variables set by CircleCI, Buildkite or GitLab CI for parallel jobs.
+// If the input is empty and no partition is detected, returns nil (no partitioning); if the input is AutoPartition and
+// no partition is detected, returns an error.
 func ParsePartition(s string) (*Partition, error) {
-	if s == "" {
-		return nil, nil
+	return parsePartition(s, os.LookupEnv)
+}
+
+func parsePartition(s string, lookupEnv func(string) (string, bool)) (*Partition, error) {
+	switch s {
+	case "":
+		return partitionFromEnv(lookupEnv)
+	case AutoPartition:
+		partition, err := partitionFromEnv(lookupEnv)
+		if err != nil {
+			return nil, err
+		}
+		if partition == nil {
+			envVars := []string{PartitionEnvVar}
+			for _, vars := range partitionEnvVars {
+				envVars = append(envVars, vars.index+"/"+vars.total)
+			}
+			return nil, errors.Errorf("partition %q was specified, but no partition environment variables are set (checked %s)", AutoPartition, strings.Join(envVars, ", "))
+		}
+		return partition, nil
+	default:
+		return parsePartitionValue(s)
+	}
+}
+
+// partitionFromEnv returns the partition specified by environment variables. Returns nil if no partition is specified
+// by the environment.
+func partitionFromEnv(lookupEnv func(string) (string, bool)) (*Partition, error) {
+	if val, ok := lookupEnv(PartitionEnvVar); ok && val != "" {
+		partition, err := parsePartitionValue(val)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value of environment variable %s", PartitionEnvVar)
+		}
+		return partition, nil
 	}
+	for _, vars := range partitionEnvVars {
+		indexVal, indexOK := lookupEnv(vars.index)
+		totalVal, totalOK := lookupEnv(vars.total)
+		if !indexOK && !totalOK {
+			continue
+		}
+		if indexVal == "" || totalVal == "" {
+			return nil, errors.Errorf("environment variables %s and %s must both be set to specify a partition", vars.index, vars.total)
+		}
+		index, err := strconv.Atoi(strings.TrimSpace(indexVal))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value of environment variable %s", vars.index)
+		}
+		if vars.oneIndexed {
+			index--
+		}
+		partition, err := parsePartitionValue(fmt.Sprintf("%d,%s", index, totalVal))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid partition specified by environment variables %s=%s and %s=%s", vars.index, indexVal, vars.total, totalVal)
+		}
+		return partition, nil
+	}
+	return nil, nil
+}
+
+// parsePartitionValue parses a partition string in the format "X,N".
+func parsePartitionValue(s string) (*Partition, error) {
 	parts := strings.Split(s, ",")
 	if len(parts) != 2 {
 		return nil, errors.Errorf("invalid partition format %q: expected format X,N (e.g., 0,4)", s)
@@ -65,6 +195,12 @@ func (p *Partition) Apply(pkgs []string) []string {
 	if p == nil || len(pkgs) == 0 {
 		return pkgs
 	}
+	if p.Timings != nil {
+		return p.applyTimings(pkgs)
+	}
+	if p.Strategy == PartitionStrategyHash {
+		return p.applyHash(pkgs)
+	}
 	// Sort for deterministic partitioning
 	sorted := slices.Sorted(slices.Values(pkgs))
 
@@ -99,6 +235,97 @@ func (p *Partition) Apply(pkgs []string) []string {
 	return sorted[start:end]
 }
 
+// applyHash assigns every package to a partition using rendezvous hashing: the package is assigned to the partition
+// for which the hash of the package path and the partition index is the highest. The partition of a package only
+// depends on its path and the total number of partitions, and changing the total number of partitions only moves the
+// packages that are assigned to the added or removed partitions. Returns the packages assigned to this partition in
+// sorted order.
+func (p *Partition) applyHash(pkgs []string) []string {
+	var assigned []string
+	for _, pkg := range slices.Sorted(slices.Values(pkgs)) {
+		partitionIdx := 0
+		var highestScore uint64
+		for i := range p.Total {
+			if score := partitionHash(pkg, i); i == 0 || score > highestScore {
+				partitionIdx, highestScore = i, score
+			}
+		}
+		if partitionIdx == p.Index {
+			assigned = append(assigned, pkg)
+		}
+	}
+	return assigned
+}
+
+// partitionHash returns the hash of the provided package path and partition index.
+func partitionHash(pkg string, partitionIdx int) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(pkg))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(strconv.Itoa(partitionIdx)))
+	// FNV does not mix the final bytes of its input well, so apply the splitmix64 finalizer to ensure that the hashes
+	// for different partition indices are independent
+	x := h.Sum64()
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// applyTimings assigns the packages to partitions using the longest-processing-time-first greedy strategy: packages
+// are considered in descending order of expected duration and each package is assigned to the partition with the
+// smallest total expected duration so far. Packages without timings are expected to take the median duration of the
+// packages that have timings. Ties are broken by package name and partition index so that every partition computes
+// the same assignment independently. Returns the packages assigned to this partition in sorted order.
+func (p *Partition) applyTimings(pkgs []string) []string {
+	defaultDuration := p.defaultDuration(pkgs)
+	durations := make(map[string]time.Duration, len(pkgs))
+	for _, pkg := range pkgs {
+		duration, ok := p.Timings[pkg]
+		if !ok {
+			duration = defaultDuration
+		}
+		durations[pkg] = duration
+	}
+
+	sorted := slices.Sorted(slices.Values(pkgs))
+	slices.SortStableFunc(sorted, func(a, b string) int {
+		return cmp.Compare(durations[b], durations[a])
+	})
+
+	totals := make([]time.Duration, p.Total)
+	var assigned []string
+	for _, pkg := range sorted {
+		partitionIdx := 0
+		for i, total := range totals {
+			if total < totals[partitionIdx] {
+				partitionIdx = i
+			}
+		}
+		totals[partitionIdx] += durations[pkg]
+		if partitionIdx == p.Index {
+			assigned = append(assigned, pkg)
+		}
+	}
+	slices.Sort(assigned)
+	return assigned
+}
+
+// defaultDuration returns the median duration of the provided packages that have timings, or
+// defaultPackageDuration if none of the packages have timings.
+func (p *Partition) defaultDuration(pkgs []string) time.Duration {
+	var known []time.Duration
+	for _, pkg := range pkgs {
+		if duration, ok := p.Timings[pkg]; ok {
+			known = append(known, duration)
+		}
+	}
+	if len(known) == 0 {
+		return defaultPackageDuration
+	}
+	slices.Sort(known)
+	return known[len(known)/2]
+}
+
 // String returns a human-readable string representation of the partition.
 func (p Partition) String() string {
 	return fmt.Sprintf("partition %d of %d", p.Index, p.Total)
@@ -15,7 +15,9 @@
 package testplugin
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -85,7 +87,7 @@ func TestParsePartition(t *testing.T) {
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			got, err := ParsePartition(tc.input)
+			got, err := parsePartition(tc.input, envLookup(nil))
 			if tc.wantErr != "" {
 				require.Error(t, err)
 				assert.Contains(t, err.Error(), tc.wantErr)
@@ -97,6 +99,86 @@ func TestParsePartition(t *testing.T) {
 	}
 }
 
+func TestParsePartitionFromEnv(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		input   string
+		env     map[string]string
+		want    *Partition
+		wantErr string
+	}{
+		{
+			name: "no environment variables returns nil",
+		},
+		{
+			name:    "auto with no environment variables returns error",
+			input:   "auto",
+			wantErr: `partition "auto" was specified, but no partition environment variables are set`,
+		},
+		{
+			name:  "generic environment variable",
+			input: "auto",
+			env:   map[string]string{"GODEL_TEST_PARTITION": "1,3", "CIRCLE_NODE_INDEX": "0", "CIRCLE_NODE_TOTAL": "2"},
+			want:  &Partition{Index: 1, Total: 3},
+		},
+		{
+			name: "CircleCI",
+			env:  map[string]string{"CIRCLE_NODE_INDEX": "2", "CIRCLE_NODE_TOTAL": "4"},
+			want: &Partition{Index: 2, Total: 4},
+		},
+		{
+			name: "Buildkite",
+			env:  map[string]string{"BUILDKITE_PARALLEL_JOB": "0", "BUILDKITE_PARALLEL_JOB_COUNT": "2"},
+			want: &Partition{Index: 0, Total: 2},
+		},
+		{
+			name:  "GitLab CI is 1-indexed",
+			input: "auto",
+			env:   map[string]string{"CI_NODE_INDEX": "3", "CI_NODE_TOTAL": "3"},
+			want:  &Partition{Index: 2, Total: 3},
+		},
+		{
+			name:  "explicit partition takes precedence over environment",
+			input: "0,2",
+			env:   map[string]string{"CIRCLE_NODE_INDEX": "1", "CIRCLE_NODE_TOTAL": "4"},
+			want:  &Partition{Index: 0, Total: 2},
+		},
+		{
+			name:    "index without total",
+			env:     map[string]string{"CIRCLE_NODE_INDEX": "1"},
+			wantErr: "environment variables CIRCLE_NODE_INDEX and CIRCLE_NODE_TOTAL must both be set to specify a partition",
+		},
+		{
+			name:    "invalid GitLab index",
+			env:     map[string]string{"CI_NODE_INDEX": "0", "CI_NODE_TOTAL": "3"},
+			wantErr: "invalid partition specified by environment variables CI_NODE_INDEX=0 and CI_NODE_TOTAL=3: partition index must be between 0 and 2, got -1",
+		},
+		{
+			name:    "invalid generic environment variable",
+			env:     map[string]string{"GODEL_TEST_PARTITION": "4"},
+			wantErr: `invalid value of environment variable GODEL_TEST_PARTITION: invalid partition format "4"`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parsePartition(tc.input, envLookup(tc.env))
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func envLookup(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		val, ok := env[key]
+		return val, ok
+	}
+}
+
 func TestPartitionApply(t *testing.T) {
 	for _, tc := range []struct {
 		name      string
@@ -178,6 +260,132 @@ func TestPartitionApply(t *testing.T) {
 	}
 }
 
+func TestPartitionApplyTimings(t *testing.T) {
+	pkgs := []string{"./a", "./b", "./c", "./d", "./e", "./f"}
+	timings := map[string]time.Duration{
+		"./a": 10 * time.Second,
+		"./b": 6 * time.Second,
+		"./c": 5 * time.Second,
+		"./d": 4 * time.Second,
+		"./e": 1 * time.Second,
+	}
+	for _, tc := range []struct {
+		name      string
+		partition *Partition
+		pkgs      []string
+		want      []string
+	}{
+		{
+			name:      "partition 0 of 2 gets the slowest package and the smallest packages",
+			partition: &Partition{Index: 0, Total: 2, Timings: timings},
+			pkgs:      pkgs,
+			// "./f" has no timings, so it is expected to take the median duration (5s)
+			want: []string{"./a", "./e", "./f"},
+		},
+		{
+			name:      "partition 1 of 2 gets the remaining packages",
+			partition: &Partition{Index: 1, Total: 2, Timings: timings},
+			pkgs:      pkgs,
+			want:      []string{"./b", "./c", "./d"},
+		},
+		{
+			name:      "packages without any timings are distributed evenly",
+			partition: &Partition{Index: 1, Total: 3, Timings: map[string]time.Duration{}},
+			pkgs:      pkgs,
+			want:      []string{"./b", "./e"},
+		},
+		{
+			name:      "more partitions than packages",
+			partition: &Partition{Index: 2, Total: 3, Timings: map[string]time.Duration{"./a": time.Second}},
+			pkgs:      []string{"./a", "./b"},
+			want:      nil,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.partition.Apply(tc.pkgs))
+		})
+	}
+}
+
+func TestPartitionApplyTimingsCoversAllPackages(t *testing.T) {
+	var pkgs []string
+	timings := make(map[string]time.Duration)
+	for i := range 50 {
+		pkg := fmt.Sprintf("./pkg%02d", i)
+		pkgs = append(pkgs, pkg)
+		if i%3 != 0 {
+			timings[pkg] = time.Duration(i*i) * time.Millisecond
+		}
+	}
+	var got []string
+	for i := range 4 {
+		got = append(got, (&Partition{Index: i, Total: 4, Timings: timings}).Apply(pkgs)...)
+	}
+	assert.ElementsMatch(t, pkgs, got)
+}
+
+func TestPartitionApplyHash(t *testing.T) {
+	var pkgs []string
+	for i := range 200 {
+		pkgs = append(pkgs, fmt.Sprintf("./pkg%03d", i))
+	}
+	assignments := func(pkgs []string, total int) map[string]int {
+		assigned := make(map[string]int)
+		for i := range total {
+			for _, pkg := range (&Partition{Index: i, Total: total, Strategy: PartitionStrategyHash}).Apply(pkgs) {
+				_, ok := assigned[pkg]
+				require.False(t, ok, "package %s assigned to multiple partitions", pkg)
+				assigned[pkg] = i
+			}
+		}
+		require.Len(t, assigned, len(pkgs))
+		return assigned
+	}
+
+	before := assignments(pkgs, 4)
+	partitionSizes := make(map[int]int)
+	for _, partitionIdx := range before {
+		partitionSizes[partitionIdx]++
+	}
+	for i := range 4 {
+		assert.InDelta(t, 50, partitionSizes[i], 20, "partition %d has %d packages", i, partitionSizes[i])
+	}
+
+	// adding and removing packages does not move any other package
+	after := assignments(append([]string{"./aaa"}, pkgs[1:]...), 4)
+	for _, pkg := range pkgs[1:] {
+		assert.Equal(t, before[pkg], after[pkg], "package %s moved", pkg)
+	}
+
+	// adding a partition only moves packages to the new partition
+	for pkg, partitionIdx := range assignments(pkgs, 5) {
+		if partitionIdx != 4 {
+			assert.Equal(t, before[pkg], partitionIdx, "package %s moved", pkg)
+		}
+	}
+}
+
+func TestParsePartitionStrategy(t *testing.T) {
+	for _, tc := range []struct {
+		input   string
+		want    PartitionStrategy
+		wantErr string
+	}{
+		{input: "", want: PartitionStrategyContiguous},
+		{input: "contiguous", want: PartitionStrategyContiguous},
+		{input: "hash", want: PartitionStrategyHash},
+		{input: "random", wantErr: `invalid partition strategy "random": must be one of "contiguous" or "hash"`},
+	} {
+		got, err := ParsePartitionStrategy(tc.input)
+		if tc.wantErr != "" {
+			assert.EqualError(t, err, tc.wantErr)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, got)
+	}
+}
+
 func TestPartitionString(t *testing.T) {
 	assert.Equal(t, "partition 0 of 4", (&Partition{Index: 0, Total: 4}).String())
 	assert.Equal(t, "partition 2 of 5", (&Partition{Index: 2, Total: 5}).String())
@@ -0,0 +1,144 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	testPlanVersion = 1
+
+	PlanFormatText = "text"
+	PlanFormatJSON = "json"
+)
+
+// TestPlan is the assignment of the packages to test to partitions. A plan can be computed using PlanTests or by an
+// external scheduler, and the packages assigned to a partition by a plan can be tested by setting Partition.Plan.
+type TestPlan struct {
+	Version int `json:"version"`
+	// Tags are the tags used to determine the packages in the plan. Informational only.
+	Tags       []string        `json:"tags,omitempty"`
+	Partitions []PlanPartition `json:"partitions"`
+}
+
+// PlanPartition contains the packages assigned to a single partition.
+type PlanPartition struct {
+	Index int `json:"index"`
+	// Packages are the paths of the packages relative to the project directory (in the format returned by
+	// PkgsToTest) for which all tests are run.
+	Packages []string `json:"packages"`
+	// SplitPackages contains the top-level tests run by the partition for the packages whose tests are split across
+	// partitions, keyed by the path of the package relative to the project directory.
+	SplitPackages map[string][]string `json:"splitPackages,omitempty"`
+}
+
+// pkgs returns the paths of all of the packages tested by the partition, including the split packages.
+func (p PlanPartition) pkgs() []string {
+	return append(slices.Clone(p.Packages), slices.Sorted(maps.Keys(p.SplitPackages))...)
+}
+
+// PlanTests returns the plan that assigns the packages that match the provided tags to every one of the partitions
+// defined by the provided partition. The Index of the provided partition is ignored. If param.SplitPackageThreshold is
+// set, the tests of the packages that have more tests than the threshold are split across the partitions.
+func PlanTests(projectDir string, tags []string, partition Partition, param TestParam) (TestPlan, error) {
+	pkgs, err := PkgsForTags(projectDir, tags, param)
+	if err != nil {
+		return TestPlan{}, err
+	}
+	var pkgTests map[string][]string
+	if param.SplitPackageThreshold > 0 && partition.Total > 1 {
+		if pkgTests, err = listPkgTests(projectDir, pkgs); err != nil {
+			return TestPlan{}, err
+		}
+	}
+	plan := TestPlan{
+		Version: testPlanVersion,
+		Tags:    tags,
+	}
+	for i := range partition.Total {
+		partition.Index = i
+		plan.Partitions = append(plan.Partitions, partition.splitPartition(pkgs, pkgTests, param.SplitPackageThreshold))
+	}
+	return plan, nil
+}
+
+// ReadTestPlan reads the plan in the provided JSON file.
+func ReadTestPlan(file string) (*TestPlan, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read test plan")
+	}
+	var plan TestPlan
+	if err := json.Unmarshal(content, &plan); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal test plan %s", file)
+	}
+	if plan.Version != testPlanVersion {
+		return nil, errors.Errorf("unsupported version of test plan %s: %d", file, plan.Version)
+	}
+	for i, partition := range plan.Partitions {
+		if partition.Index != i {
+			return nil, errors.Errorf("invalid test plan %s: partition at position %d has index %d", file, i, partition.Index)
+		}
+	}
+	return &plan, nil
+}
+
+// Write writes the plan to the provided writer in the provided format (PlanFormatText or PlanFormatJSON).
+func (p TestPlan) Write(w io.Writer, format string) error {
+	switch format {
+	case PlanFormatJSON:
+		content, err := json.MarshalIndent(p, "", "  ")
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal test plan")
+		}
+		_, err = fmt.Fprintln(w, string(content))
+		return err
+	case PlanFormatText:
+		for _, partition := range p.Partitions {
+			if _, err := fmt.Fprintf(w, "%v (%d package(s)):\n", Partition{Index: partition.Index, Total: len(p.Partitions)}, len(partition.Packages)+len(partition.SplitPackages)); err != nil {
+				return err
+			}
+			for _, pkg := range partition.Packages {
+				if _, err := fmt.Fprintf(w, "\t%s\n", pkg); err != nil {
+					return err
+				}
+			}
+			for _, pkg := range slices.Sorted(maps.Keys(partition.SplitPackages)) {
+				if _, err := fmt.Fprintf(w, "\t%s (split: %d test(s))\n", pkg, len(partition.SplitPackages[pkg])); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	default:
+		return errors.Errorf("invalid plan format %q: must be one of %q or %q", format, PlanFormatText, PlanFormatJSON)
+	}
+}
+
+// partition returns the packages and tests assigned to the provided partition by the plan.
+func (p TestPlan) partition(partition Partition) (PlanPartition, error) {
+	if partition.Total != len(p.Partitions) {
+		return PlanPartition{}, errors.Errorf("%v does not match the test plan, which has %d partition(s)", partition, len(p.Partitions))
+	}
+	return p.Partitions[partition.Index], nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanTests(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module testmod\n\ngo 1.21\n"), 0644))
+	for _, pkg := range []string{"a", "b", "c"} {
+		pkgDir := filepath.Join(tmpDir, pkg)
+		require.NoError(t, os.MkdirAll(pkgDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(pkgDir, "foo.go"), []byte("package foo\n"), 0644))
+	}
+
+	plan, err := PlanTests(tmpDir, nil, Partition{Total: 4}, TestParam{})
+	require.NoError(t, err)
+	assert.Equal(t, TestPlan{
+		Version: 1,
+		Partitions: []PlanPartition{
+			{Index: 0, Packages: []string{"./a"}},
+			{Index: 1, Packages: []string{"./b"}},
+			{Index: 2, Packages: []string{"./c"}},
+			{Index: 3, Packages: []string{}},
+		},
+	}, plan)
+
+	var text bytes.Buffer
+	require.NoError(t, plan.Write(&text, PlanFormatText))
+	assert.Equal(t, `partition 0 of 4 (1 package(s)):
+	./a
+partition 1 of 4 (1 package(s)):
+	./b
+partition 2 of 4 (1 package(s)):
+	./c
+partition 3 of 4 (0 package(s)):
+`, text.String())
+
+	// the JSON plan can be read back and used to determine the packages of a partition
+	planFile := filepath.Join(tmpDir, "plan.json")
+	var planJSON bytes.Buffer
+	require.NoError(t, plan.Write(&planJSON, PlanFormatJSON))
+	require.NoError(t, os.WriteFile(planFile, planJSON.Bytes(), 0644))
+	readPlan, err := ReadTestPlan(planFile)
+	require.NoError(t, err)
+	assert.Equal(t, plan, *readPlan)
+
+	pkgs, err := PkgsToTest(tmpDir, nil, &Partition{Index: 1, Total: 4, Plan: readPlan}, TestParam{}, &text)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"./b"}, pkgs)
+
+	_, err = PkgsToTest(tmpDir, nil, &Partition{Index: 1, Total: 2, Plan: readPlan}, TestParam{}, &text)
+	assert.EqualError(t, err, "partition 1 of 2 does not match the test plan, which has 4 partition(s)")
+}
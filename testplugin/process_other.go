@@ -0,0 +1,41 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !unix
+
+package testplugin
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup is a no-op on platforms that do not support process groups.
+func setProcessGroup(*exec.Cmd) {}
+
+// quitProcessGroup kills the provided process on platforms that do not support SIGQUIT, so no goroutine dumps are
+// written.
+func quitProcessGroup(process *os.Process) error {
+	return process.Kill()
+}
+
+// signalProcessGroup kills the provided process on platforms that do not support sending signals to other processes.
+func signalProcessGroup(process *os.Process, _ os.Signal) error {
+	return process.Kill()
+}
+
+// killProcessGroup kills the provided process.
+func killProcessGroup(process *os.Process) error {
+	return process.Kill()
+}
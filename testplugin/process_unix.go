@@ -0,0 +1,48 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unix
+
+package testplugin
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup configures the provided command to run in its own process group.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// quitProcessGroup sends SIGQUIT to the process group of the provided process. The go command ignores the signal
+// while it runs test binaries, and the test binaries write goroutine dumps and exit.
+func quitProcessGroup(process *os.Process) error {
+	return syscall.Kill(-process.Pid, syscall.SIGQUIT)
+}
+
+// signalProcessGroup sends the provided signal to the process group of the provided process.
+func signalProcessGroup(process *os.Process, sig os.Signal) error {
+	sysSig, ok := sig.(syscall.Signal)
+	if !ok {
+		return process.Signal(sig)
+	}
+	return syscall.Kill(-process.Pid, sysSig)
+}
+
+// killProcessGroup kills all of the processes in the process group of the provided process.
+func killProcessGroup(process *os.Process) error {
+	return syscall.Kill(-process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,200 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultProgressInterval is the interval at which progress lines are printed if the output is not a terminal and
+	// the interval is not configured.
+	DefaultProgressInterval = time.Minute
+
+	// progressRedrawInterval is the interval at which the progress line is redrawn if the output is a terminal.
+	progressRedrawInterval = 200 * time.Millisecond
+	// progressMaxRunningPkgs is the maximum number of running packages that are listed in the progress line.
+	progressMaxRunningPkgs = 3
+
+	// clearLine moves the cursor to the start of the line and clears the line.
+	clearLine = "\r\033[K"
+)
+
+// progressReporter reports the progress of a run based on the events of the packages. If the output is a terminal, a
+// progress line is kept at the bottom of the output and redrawn as the packages progress. Otherwise, a progress line is
+// printed at a fixed interval so that long runs without any other output show that they are progressing.
+//
+// All of the other output of the run must be written using the reporter (which implements io.Writer) so that the
+// progress line can be cleared before the output is written.
+type progressReporter struct {
+	// mu guards all of the fields below and serializes the writes to "out".
+	mu  sync.Mutex
+	out io.Writer
+	// tty is true if the output is a terminal, in which case the progress line is redrawn in place.
+	tty   bool
+	width int
+	start time.Time
+	// total is the number of packages to test, or 0 if it is not known in advance (for example, when packages are
+	// claimed from a queue).
+	total int
+	// statuses contains the status of every package that completed keyed by package. The status of a package that is
+	// tested again (for example, when failed tests are retried) is updated.
+	statuses map[string]string
+	// running contains the packages that have started but not completed in the order in which they started.
+	running []string
+	// shownLine is the progress line that is currently shown at the bottom of the output, or empty if no progress line
+	// is shown. atLineStart is true if the last output that was written ended with a newline (so the progress line can
+	// be shown without splitting a line of output).
+	shownLine   string
+	atLineStart bool
+	done        chan struct{}
+	wg          sync.WaitGroup
+}
+
+// startProgressReporter starts reporting the progress of testing "total" packages to the provided output. If the output
+// is not a terminal, a progress line is printed every "interval".
+func startProgressReporter(out io.Writer, total int, interval time.Duration) *progressReporter {
+	p := &progressReporter{
+		out:         out,
+		tty:         isTerminal(out),
+		width:       terminalWidth(out),
+		start:       time.Now(),
+		total:       total,
+		statuses:    make(map[string]string),
+		atLineStart: true,
+		done:        make(chan struct{}),
+	}
+	tickInterval := interval
+	if p.tty {
+		tickInterval = progressRedrawInterval
+	}
+	p.wg.Go(func() {
+		ticker := time.NewTicker(tickInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.done:
+				return
+			case <-ticker.C:
+				p.tick()
+			}
+		}
+	})
+	return p
+}
+
+// stop stops reporting progress and clears the progress line. Output that is written after this is called is written
+// to the output unmodified. Safe to call on a nil reporter.
+func (p *progressReporter) stop() {
+	if p == nil {
+		return
+	}
+	close(p.done)
+	p.wg.Wait()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clearLocked()
+}
+
+// event records the provided event if it is the start or completion of a package. Safe to call on a nil reporter.
+func (p *progressReporter) event(ev testEvent) {
+	if p == nil || ev.Package == "" || ev.Test != "" {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case ev.Action == actionStart:
+		if !slices.Contains(p.running, ev.Package) {
+			p.running = append(p.running, ev.Package)
+		}
+	case ev.isTerminal():
+		p.running = slices.DeleteFunc(p.running, func(pkg string) bool {
+			return pkg == ev.Package
+		})
+		p.statuses[ev.Package] = ev.Action
+	}
+}
+
+func (p *progressReporter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clearLocked()
+	if len(b) > 0 {
+		p.atLineStart = b[len(b)-1] == '\n'
+	}
+	return p.out.Write(b)
+}
+
+func (p *progressReporter) tick() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.tty {
+		_, _ = fmt.Fprintf(p.out, "Progress: %s (elapsed %v)\n", p.statusLocked(), time.Since(p.start).Round(time.Second))
+		return
+	}
+	if !p.atLineStart {
+		return
+	}
+	line := p.statusLocked()
+	if p.width > 0 && len(line) >= p.width {
+		// the line must fit on a single line of the terminal so that it can be cleared
+		line = line[:p.width-1]
+	}
+	if line == p.shownLine {
+		return
+	}
+	_, _ = io.WriteString(p.out, clearLine+line)
+	p.shownLine = line
+}
+
+func (p *progressReporter) clearLocked() {
+	if p.shownLine != "" {
+		_, _ = io.WriteString(p.out, clearLine)
+		p.shownLine = ""
+	}
+}
+
+// statusLocked returns the description of the progress of the run, for example "37/212 packages done, 3 failed,
+// running: pkg/a, pkg/b".
+func (p *progressReporter) statusLocked() string {
+	var status bytes.Buffer
+	if p.total > 0 {
+		_, _ = fmt.Fprintf(&status, "%d/%d packages done", len(p.statuses), p.total)
+	} else {
+		_, _ = fmt.Fprintf(&status, "%d packages done", len(p.statuses))
+	}
+	failed := 0
+	for _, s := range p.statuses {
+		if s == actionFail {
+			failed++
+		}
+	}
+	_, _ = fmt.Fprintf(&status, ", %d failed", failed)
+	if len(p.running) > 0 {
+		running := strings.Join(p.running[:min(len(p.running), progressMaxRunningPkgs)], ", ")
+		if len(p.running) > progressMaxRunningPkgs {
+			running += fmt.Sprintf(" (+%d more)", len(p.running)-progressMaxRunningPkgs)
+		}
+		_, _ = fmt.Fprintf(&status, ", running: %s", running)
+	}
+	return status.String()
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressReporterPrintsProgressLines(t *testing.T) {
+	var out syncBuffer
+	p := startProgressReporter(&out, 3, 10*time.Millisecond)
+	for _, ev := range []testEvent{
+		{Action: actionStart, Package: "pkg/a"},
+		{Action: actionStart, Package: "pkg/b"},
+		{Action: actionRun, Package: "pkg/b", Test: "TestB"},
+		{Action: actionFail, Package: "pkg/b", Test: "TestB"},
+		{Action: actionFail, Package: "pkg/b"},
+	} {
+		p.event(ev)
+	}
+	_, _ = fmt.Fprintln(p, "FAIL\tpkg/b\t0.01s")
+	assert.Eventually(t, func() bool {
+		return bytes.Contains(out.Bytes(), []byte("Progress: 1/3 packages done, 1 failed, running: pkg/a (elapsed "))
+	}, 5*time.Second, 10*time.Millisecond)
+	p.stop()
+
+	assert.Contains(t, out.String(), "FAIL\tpkg/b\t0.01s\n")
+	assert.NotContains(t, out.String(), clearLine)
+}
+
+func TestProgressReporterStatus(t *testing.T) {
+	for i, tc := range []struct {
+		total    int
+		statuses map[string]string
+		running  []string
+		want     string
+	}{
+		{
+			total: 2,
+			want:  "0/2 packages done, 0 failed",
+		},
+		{
+			total:    4,
+			statuses: map[string]string{"pkg/a": actionPass, "pkg/b": actionFail, "pkg/c": actionSkip},
+			running:  []string{"pkg/d"},
+			want:     "3/4 packages done, 1 failed, running: pkg/d",
+		},
+		{
+			statuses: map[string]string{"pkg/a": actionPass},
+			running:  []string{"pkg/b", "pkg/c", "pkg/d", "pkg/e", "pkg/f"},
+			want:     "1 packages done, 0 failed, running: pkg/b, pkg/c, pkg/d (+2 more)",
+		},
+	} {
+		p := &progressReporter{
+			total:    tc.total,
+			statuses: tc.statuses,
+			running:  tc.running,
+		}
+		if p.statuses == nil {
+			p.statuses = make(map[string]string)
+		}
+		assert.Equal(t, tc.want, p.statusLocked(), "Case %d", i)
+	}
+}
+
+// syncBuffer is a bytes.Buffer that is safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return bytes.Clone(b.buf.Bytes())
+}
+
+func (b *syncBuffer) String() string {
+	return string(b.Bytes())
+}
@@ -0,0 +1,679 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	queueVersion = 2
+
+	queueFileName     = "queue.json"
+	queueLockFileName = "queue.lock"
+	queueResultsDir   = "results"
+	queueSummaryFile  = "summary.json"
+
+	// queueLockRetryInterval is the interval at which a node that is waiting for the queue lock retries.
+	queueLockRetryInterval = 50 * time.Millisecond
+	// queueLockStaleAfter is the age after which a lock is considered to be held by a node that crashed. The lock is
+	// only held while the small queue file is read and written, so a lock that is this old is never held legitimately.
+	queueLockStaleAfter = 30 * time.Second
+	// queueHeartbeatInterval is the interval at which a node records in the queue that it is still running while it
+	// tests packages.
+	queueHeartbeatInterval = 10 * time.Second
+	// queueNodeSilentAfter is the duration without any update from a node after which the node is considered to have
+	// crashed and the packages that it claimed are re-queued.
+	queueNodeSilentAfter = 2 * time.Minute
+	// queueWaitInterval is the interval at which a node that has finished checks whether the other nodes have finished.
+	queueWaitInterval = time.Second
+)
+
+// QueueIDEnvVar is the environment variable that can be set to the ID of the run that uses a queue to specify it
+// independently of the CI provider.
+const QueueIDEnvVar = "GODEL_TEST_QUEUE_ID"
+
+// queueIDEnvVars are the environment variables set by CI providers to an ID of the pipeline that a job is part of,
+// which is shared by the parallel nodes of the job, in the order in which they are checked.
+var queueIDEnvVars = []string{
+	"CIRCLE_WORKFLOW_ID",
+	"BUILDKITE_BUILD_ID",
+	"CI_PIPELINE_ID",
+}
+
+// QueueIDFromEnv returns the ID of the run that uses a queue specified by the GODEL_TEST_QUEUE_ID environment variable
+// or by the environment variables set by CircleCI, Buildkite or GitLab CI for pipelines. Returns an empty string if no
+// ID is specified by the environment.
+func QueueIDFromEnv() string {
+	return queueIDFromEnv(os.LookupEnv)
+}
+
+func queueIDFromEnv(lookupEnv func(string) (string, bool)) string {
+	for _, envVar := range append([]string{QueueIDEnvVar}, queueIDEnvVars...) {
+		if val, ok := lookupEnv(envVar); ok && val != "" {
+			return val
+		}
+	}
+	return ""
+}
+
+// queueState is the content of the queue file, which is only read and written while the queue lock is held.
+type queueState struct {
+	Version int `json:"version"`
+	// ID is the ID of the run that uses the queue, which is shared by all of its nodes.
+	ID string `json:"id"`
+	// Packages are the packages to test in the format returned by PkgsToTest in the order in which they are claimed.
+	Packages []string `json:"packages"`
+	// Next is the index in Packages of the next package to claim.
+	Next int `json:"next"`
+	// Requeued are the packages that were claimed by nodes that crashed, which are claimed before Packages[Next:].
+	Requeued []string `json:"requeued,omitempty"`
+	// Claims contains the node that claimed every package that has been claimed keyed by package.
+	Claims map[string]string `json:"claims"`
+	// Nodes contains the state of every node that joined the queue keyed by node.
+	Nodes map[string]queueNode `json:"nodes"`
+	// Completed is true once all of the nodes have finished and the summary of the queue has been written.
+	Completed bool `json:"completed,omitempty"`
+}
+
+// queueNode is the state of a node that joined the queue.
+type queueNode struct {
+	// LastSeen is the last time at which the node updated the queue.
+	LastSeen time.Time `json:"lastSeen"`
+	Finished bool      `json:"finished"`
+	// Lost is true if the node has not updated the queue for queueNodeSilentAfter before it finished, in which case
+	// the packages that it claimed were re-queued.
+	Lost bool `json:"lost,omitempty"`
+}
+
+// done returns true if the node will not test any more packages.
+func (n queueNode) done() bool {
+	return n.Finished || n.Lost
+}
+
+// queueResults are the results of the packages tested by one or more nodes. Every node writes the results of the
+// packages that it tested to the results directory of the queue when it finishes, and the last node to finish writes
+// the merged results of all of the nodes as the summary of the queue.
+type queueResults struct {
+	Version int      `json:"version"`
+	Nodes   []string `json:"nodes"`
+	// LostNodes are the nodes that stopped updating the queue before they finished.
+	LostNodes []string         `json:"lostNodes,omitempty"`
+	Packages  []queuePkgResult `json:"packages"`
+	// FailedPackages are the import paths of the packages that failed.
+	FailedPackages []string `json:"failedPackages,omitempty"`
+}
+
+// queuePkgResult is the result of testing a single package claimed from the queue.
+type queuePkgResult struct {
+	// Package is the import path of the package.
+	Package string `json:"package"`
+	Node    string `json:"node,omitempty"`
+	Status  string `json:"status"`
+	// Elapsed is the duration of testing the package in seconds.
+	Elapsed     float64  `json:"elapsed"`
+	FailedTests []string `json:"failedTests,omitempty"`
+	// Error describes why the package has no result if no node reported one, in which case the package is failed.
+	Error string `json:"error,omitempty"`
+}
+
+// testQueue is a queue of packages in a directory shared by multiple nodes (for example, a network file system or a
+// CI workspace) from which every node claims packages to test one at a time.
+type testQueue struct {
+	dir     string
+	modPath string
+	node    string
+	// summary is the summary of the queue if the queue had already completed when the node joined it.
+	summary *queueResults
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// joinTestQueue joins the queue of the run with the provided ID in the provided directory as a new node. The queue is
+// created with the provided packages of the module with the provided path if it does not exist; otherwise, the provided
+// packages must match the packages of the existing queue. The queue of a different run is replaced if that run has
+// completed or all of its nodes stopped, and is an error otherwise. If the queue of the run has already completed, the
+// node does not join it and the summary of the queue is set instead.
+func joinTestQueue(dir, id, modPath string, pkgs []string) (*testQueue, error) {
+	if err := os.MkdirAll(filepath.Join(dir, queueResultsDir), 0755); err != nil {
+		return nil, errors.Wrapf(err, "failed to create queue directory")
+	}
+	node, err := newQueueNodeName()
+	if err != nil {
+		return nil, err
+	}
+	q := &testQueue{
+		dir:     dir,
+		modPath: modPath,
+		node:    node,
+		done:    make(chan struct{}),
+	}
+	if err := q.update(func(state *queueState) error {
+		switch {
+		case state.Version != 0 && state.ID != id && !state.Completed && state.hasLiveNodes():
+			return errors.Errorf("the queue in %s is used by run %q, which has not completed: a queue directory can only be used by one run at a time", dir, state.ID)
+		case state.Version != 0 && state.ID != id:
+			// the queue was used by a previous run
+			if err := resetQueueDir(dir); err != nil {
+				return err
+			}
+			fallthrough
+		case state.Version == 0:
+			*state = queueState{
+				Version:  queueVersion,
+				ID:       id,
+				Packages: pkgs,
+				Claims:   make(map[string]string),
+				Nodes:    make(map[string]queueNode),
+			}
+		case !slices.Equal(state.Packages, pkgs):
+			return errors.Errorf("the packages to test do not match the packages of the queue in %s, which was created by another node: all nodes must test the same packages", dir)
+		}
+		if state.Completed {
+			summary, err := readQueueResults(filepath.Join(dir, queueSummaryFile))
+			if err != nil {
+				return err
+			}
+			q.summary = &summary
+			return nil
+		}
+		state.Nodes[q.node] = queueNode{LastSeen: time.Now()}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// hasLiveNodes returns true if any node of the queue has not finished and has updated the queue within
+// queueNodeSilentAfter.
+func (s *queueState) hasLiveNodes() bool {
+	for _, n := range s.Nodes {
+		if !n.done() && time.Since(n.LastSeen) <= queueNodeSilentAfter {
+			return true
+		}
+	}
+	return false
+}
+
+// resetQueueDir removes the results and the summary of the queue in the provided directory.
+func resetQueueDir(dir string) error {
+	resultFiles, err := filepath.Glob(filepath.Join(dir, queueResultsDir, "*.json"))
+	if err != nil {
+		return errors.Wrapf(err, "failed to list queue results")
+	}
+	for _, file := range append(resultFiles, filepath.Join(dir, queueSummaryFile)) {
+		if err := removeIfExists(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startHeartbeat starts recording in the queue that the node is still running at queueHeartbeatInterval so that the
+// other nodes do not re-queue the packages that it claimed while it tests a package that takes a long time.
+func (q *testQueue) startHeartbeat() {
+	q.wg.Go(func() {
+		ticker := time.NewTicker(queueHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-q.done:
+				return
+			case <-ticker.C:
+				// a heartbeat that fails is not fatal: the node is only considered to have crashed if it does not
+				// update the queue for much longer than the interval
+				_ = q.update(func(*queueState) error { return nil })
+			}
+		}
+	})
+}
+
+// stopHeartbeat stops the heartbeat started by startHeartbeat.
+func (q *testQueue) stopHeartbeat() {
+	close(q.done)
+	q.wg.Wait()
+}
+
+// claim claims the next package in the queue. Packages that were claimed by nodes that crashed are claimed before the
+// packages that have not been claimed yet. Returns false if all of the packages have been claimed.
+func (q *testQueue) claim() (string, bool, error) {
+	var pkg string
+	err := q.update(func(state *queueState) error {
+		requeueLostNodes(state, q.node)
+		switch {
+		case len(state.Requeued) > 0:
+			pkg = state.Requeued[0]
+			state.Requeued = state.Requeued[1:]
+		case state.Next < len(state.Packages):
+			pkg = state.Packages[state.Next]
+			state.Next++
+		default:
+			return nil
+		}
+		state.Claims[pkg] = q.node
+		return nil
+	})
+	return pkg, pkg != "", err
+}
+
+// requeueLostNodes marks the nodes other than the provided node that have not updated the queue for
+// queueNodeSilentAfter before they finished as lost and re-queues the packages that they claimed.
+func requeueLostNodes(state *queueState, node string) {
+	for name, n := range state.Nodes {
+		if name == node || n.done() || time.Since(n.LastSeen) <= queueNodeSilentAfter {
+			continue
+		}
+		n.Lost = true
+		state.Nodes[name] = n
+		for _, pkg := range state.Packages {
+			if state.Claims[pkg] == name {
+				delete(state.Claims, pkg)
+				state.Requeued = append(state.Requeued, pkg)
+			}
+		}
+	}
+}
+
+// finish writes the results of the packages tested by the node and marks the node as finished. The node then waits
+// until all of the other nodes have finished or are lost, and the first node to observe this writes the merged results
+// of all of the nodes as the summary of the queue and prints it. Returns an error if any package that was not tested by
+// this node failed or was not tested (see queueFailuresError), so that every node fails if any package of the queue
+// failed.
+func (q *testQueue) finish(results *testResults, stdout io.Writer) error {
+	nodeResults := queueResults{
+		Version: queueVersion,
+		Nodes:   []string{q.node},
+	}
+	for _, pkg := range results.pkgs {
+		nodeResults.Packages = append(nodeResults.Packages, queuePkgResult{
+			Package:     pkg.Name,
+			Node:        q.node,
+			Status:      pkg.Status,
+			Elapsed:     pkg.Elapsed.Seconds(),
+			FailedTests: pkg.failedTests(),
+		})
+	}
+	nodeResults.FailedPackages = results.failedPkgs()
+	if err := writeQueueResults(filepath.Join(q.dir, queueResultsDir, q.node+".json"), nodeResults); err != nil {
+		return err
+	}
+
+	for {
+		var summary *queueResults
+		var wroteSummary bool
+		if err := q.update(func(state *queueState) error {
+			n := state.Nodes[q.node]
+			n.Finished = true
+			state.Nodes[q.node] = n
+			if state.Completed {
+				completed, err := readQueueResults(filepath.Join(q.dir, queueSummaryFile))
+				if err != nil {
+					return err
+				}
+				summary = &completed
+				return nil
+			}
+			requeueLostNodes(state, q.node)
+			for _, other := range state.Nodes {
+				if !other.done() {
+					return nil
+				}
+			}
+			// the summary is written while the lock is held so that it is only written once and so that a node that
+			// joins the queue after it has completed can read it
+			merged, err := q.mergeResults(state)
+			if err != nil {
+				return err
+			}
+			if err := writeQueueResults(filepath.Join(q.dir, queueSummaryFile), merged); err != nil {
+				return err
+			}
+			state.Completed = true
+			summary = &merged
+			wroteSummary = true
+			return nil
+		}); err != nil {
+			return err
+		}
+		if summary != nil {
+			if wroteSummary {
+				printQueueSummary(stdout, *summary)
+			}
+			return queueFailuresError(q.dir, *summary, nodeResults.FailedPackages)
+		}
+		time.Sleep(queueWaitInterval)
+	}
+}
+
+// mergeResults returns the merged results of all of the nodes in the results directory of the queue with the provided
+// state. The result of every package is the result reported by the node that claimed it last: a package that was not
+// claimed by any node or for which the node that claimed it did not report a result is reported as failed.
+func (q *testQueue) mergeResults(state *queueState) (queueResults, error) {
+	resultFiles, err := filepath.Glob(filepath.Join(q.dir, queueResultsDir, "*.json"))
+	if err != nil {
+		return queueResults{}, errors.Wrapf(err, "failed to list queue results")
+	}
+	nodeResults := make(map[string]queueResults)
+	for _, resultFile := range resultFiles {
+		results, err := readQueueResults(resultFile)
+		if err != nil {
+			return queueResults{}, err
+		}
+		for _, node := range results.Nodes {
+			nodeResults[node] = results
+		}
+	}
+
+	merged := queueResults{
+		Version: queueVersion,
+		Nodes:   slices.Sorted(maps.Keys(nodeResults)),
+	}
+	for _, node := range slices.Sorted(maps.Keys(state.Nodes)) {
+		if state.Nodes[node].Lost {
+			merged.LostNodes = append(merged.LostNodes, node)
+		}
+	}
+	for _, pkg := range state.Packages {
+		importPath := importPkgPath(q.modPath, pkg)
+		node := state.Claims[pkg]
+		results, reported := nodeResults[node]
+		i := slices.IndexFunc(results.Packages, func(result queuePkgResult) bool {
+			return result.Package == importPath
+		})
+		switch {
+		case node == "":
+			merged.Packages = append(merged.Packages, queuePkgResult{
+				Package: importPath,
+				Status:  actionFail,
+				Error:   "not tested by any node",
+			})
+		case !reported || i == -1:
+			merged.Packages = append(merged.Packages, queuePkgResult{
+				Package: importPath,
+				Node:    node,
+				Status:  actionFail,
+				Error:   fmt.Sprintf("claimed by node %s, which did not report its result", node),
+			})
+		default:
+			merged.Packages = append(merged.Packages, results.Packages[i])
+			if !slices.Contains(results.FailedPackages, importPath) {
+				continue
+			}
+		}
+		merged.FailedPackages = append(merged.FailedPackages, importPath)
+	}
+	slices.SortFunc(merged.Packages, func(a, b queuePkgResult) int {
+		return strings.Compare(a.Package, b.Package)
+	})
+	slices.Sort(merged.FailedPackages)
+	return merged, nil
+}
+
+// printQueueSummary prints the provided summary of the queue, which lists the failed packages along with the nodes
+// that tested them.
+func printQueueSummary(stdout io.Writer, summary queueResults) {
+	outputParts := []string{fmt.Sprintf("Queue summary: %d package(s) tested by %d node(s), %d failed", len(summary.Packages), len(summary.Nodes), len(summary.FailedPackages))}
+	for _, pkg := range summary.Packages {
+		if slices.Contains(summary.FailedPackages, pkg.Package) {
+			outputParts = append(outputParts, pkg.failure())
+		}
+	}
+	if len(summary.LostNodes) > 0 {
+		outputParts = append(outputParts, fmt.Sprintf("%d node(s) stopped responding: %s", len(summary.LostNodes), strings.Join(summary.LostNodes, ", ")))
+	}
+	_, _ = fmt.Fprintln(stdout, strings.Join(outputParts, "\n\t"))
+}
+
+// queueFailuresError returns the error that reports the packages of the provided summary of the queue in the provided
+// directory that failed or were not tested other than the provided packages (the packages that failed on the node,
+// which are reported separately), or nil if there are none.
+func queueFailuresError(dir string, summary queueResults, nodeFailedPkgs []string) error {
+	var failures []string
+	for _, pkg := range summary.Packages {
+		if slices.Contains(summary.FailedPackages, pkg.Package) && !slices.Contains(nodeFailedPkgs, pkg.Package) {
+			failures = append(failures, pkg.failure())
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return errors.Errorf("%d package(s) of the queue in %s failed on other nodes or were not tested:\n\t%s", len(failures), dir, strings.Join(failures, "\n\t"))
+}
+
+// failure describes the failure of the package: the node that tested it, or why it has no result.
+func (r queuePkgResult) failure() string {
+	if r.Error != "" {
+		return fmt.Sprintf("%s (%s)", r.Package, r.Error)
+	}
+	return fmt.Sprintf("%s (%s)", r.Package, r.Node)
+}
+
+// update applies the provided function to the state of the queue while holding the queue lock and writes the
+// updated state. Every update records that the node is still running.
+func (q *testQueue) update(fn func(state *queueState) error) (rErr error) {
+	lockFile := filepath.Join(q.dir, queueLockFileName)
+	token, err := acquireQueueLock(lockFile, q.node)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := releaseQueueLock(lockFile, token); err != nil && rErr == nil {
+			rErr = err
+		}
+	}()
+
+	queueFile := filepath.Join(q.dir, queueFileName)
+	var state queueState
+	content, err := os.ReadFile(queueFile)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(content, &state); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal queue file %s", queueFile)
+		}
+		if state.Version != queueVersion {
+			return errors.Errorf("unsupported version of queue file %s: %d", queueFile, state.Version)
+		}
+	case !os.IsNotExist(err):
+		return errors.Wrapf(err, "failed to read queue file")
+	}
+	if n, ok := state.Nodes[q.node]; ok {
+		// a node that was considered lost but is still running is no longer lost, although the packages that it
+		// claimed before have been re-queued
+		n.LastSeen = time.Now()
+		n.Lost = false
+		state.Nodes[q.node] = n
+	}
+	if err := fn(&state); err != nil {
+		return err
+	}
+	content, err = json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal queue")
+	}
+	// the file is written and then renamed so that a node that crashes while writing cannot corrupt the queue
+	tmpFile := queueFile + "." + token + ".tmp"
+	if err := os.WriteFile(tmpFile, append(content, '\n'), 0644); err != nil {
+		return errors.Wrapf(err, "failed to write queue file")
+	}
+	// the queue is only written if the lock was not broken by another node in the meantime
+	if err := checkQueueLock(lockFile, token); err != nil {
+		_ = os.Remove(tmpFile)
+		return err
+	}
+	if err := os.Rename(tmpFile, queueFile); err != nil {
+		return errors.Wrapf(err, "failed to write queue file")
+	}
+	return nil
+}
+
+// acquireQueueLock acquires the lock represented by the provided file by creating it exclusively, which is atomic
+// even on network file systems, and returns the token written to the lock file that identifies the owner of the lock.
+// Waits until the lock is released if it is held by another node.
+func acquireQueueLock(lockFile, node string) (string, error) {
+	suffix, err := randomHex(4)
+	if err != nil {
+		return "", err
+	}
+	token := node + "-" + suffix
+	for {
+		f, err := os.OpenFile(lockFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, writeErr := f.WriteString(token)
+			if err := f.Close(); err != nil && writeErr == nil {
+				writeErr = err
+			}
+			if writeErr != nil {
+				_ = os.Remove(lockFile)
+				return "", errors.Wrapf(writeErr, "failed to acquire queue lock")
+			}
+			return token, nil
+		}
+		if !os.IsExist(err) {
+			return "", errors.Wrapf(err, "failed to acquire queue lock")
+		}
+		if err := breakStaleQueueLock(lockFile, token); err != nil {
+			return "", err
+		}
+		time.Sleep(queueLockRetryInterval)
+	}
+}
+
+// breakStaleQueueLock removes the provided lock file if it is older than queueLockStaleAfter, in which case it is held
+// by a node that crashed. The lock is first renamed to a name that is unique to the provided token, which is atomic, so
+// that only one node can break a given lock. If the lock that was renamed is not the stale lock (because another node
+// broke the stale lock and acquired a new lock in the meantime), it is restored.
+func breakStaleQueueLock(lockFile, token string) error {
+	info, err := os.Stat(lockFile)
+	if err != nil || time.Since(info.ModTime()) <= queueLockStaleAfter {
+		// the lock was released or is held legitimately
+		return nil
+	}
+	staleOwner, err := os.ReadFile(lockFile)
+	if err != nil {
+		return nil
+	}
+	brokenLockFile := lockFile + "." + token + ".broken"
+	if err := os.Rename(lockFile, brokenLockFile); err != nil {
+		// another node broke the lock first
+		return nil
+	}
+	owner, err := os.ReadFile(brokenLockFile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to break queue lock")
+	}
+	if brokenInfo, err := os.Stat(brokenLockFile); err == nil && string(owner) == string(staleOwner) && time.Since(brokenInfo.ModTime()) > queueLockStaleAfter {
+		return removeIfExists(brokenLockFile)
+	}
+	// the lock is held by a live node: it is restored with a hard link, which fails rather than replacing a lock that
+	// was acquired in the meantime
+	if err := os.Link(brokenLockFile, lockFile); err != nil && !os.IsExist(err) {
+		return errors.Wrapf(err, "failed to restore queue lock")
+	}
+	return removeIfExists(brokenLockFile)
+}
+
+// checkQueueLock returns an error if the provided lock file is not held with the provided token.
+func checkQueueLock(lockFile, token string) error {
+	owner, err := os.ReadFile(lockFile)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to read queue lock")
+	}
+	if string(owner) != token {
+		return errors.Errorf("the queue lock %s was broken by another node because it was held for longer than %v", lockFile, queueLockStaleAfter)
+	}
+	return nil
+}
+
+// releaseQueueLock releases the provided lock file if it is still held with the provided token.
+func releaseQueueLock(lockFile, token string) error {
+	if err := checkQueueLock(lockFile, token); err != nil {
+		return err
+	}
+	if err := os.Remove(lockFile); err != nil {
+		return errors.Wrapf(err, "failed to release queue lock")
+	}
+	return nil
+}
+
+func removeIfExists(file string) error {
+	if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to remove %s", file)
+	}
+	return nil
+}
+
+func readQueueResults(file string) (queueResults, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return queueResults{}, errors.Wrapf(err, "failed to read queue results")
+	}
+	var results queueResults
+	if err := json.Unmarshal(content, &results); err != nil {
+		return queueResults{}, errors.Wrapf(err, "failed to unmarshal queue results %s", file)
+	}
+	return results, nil
+}
+
+func writeQueueResults(file string, results queueResults) error {
+	content, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal queue results")
+	}
+	if err := os.WriteFile(file, append(content, '\n'), 0644); err != nil {
+		return errors.Wrapf(err, "failed to write queue results")
+	}
+	return nil
+}
+
+var invalidQueueNodeCharsRegexp = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// newQueueNodeName returns a unique name for a node of a queue that is also a valid file name. The name includes the
+// host name and process ID to make it easy to identify the node that tested a package.
+func newQueueNodeName() (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	suffix, err := randomHex(4)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%d-%s", invalidQueueNodeCharsRegexp.ReplaceAllString(hostname, "_"), os.Getpid(), suffix), nil
+}
+
+// randomHex returns the hex encoding of the provided number of random bytes.
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrapf(err, "failed to generate random bytes")
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,248 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTestCmdQueue(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module testmod\n\ngo 1.21\n"), 0644))
+	for _, pkg := range []string{"a", "b", "c", "d", "fail"} {
+		content := "package " + pkg + "\n\nimport \"testing\"\n\nfunc TestPass(t *testing.T) {}\n"
+		if pkg == "fail" {
+			content += "\nfunc TestFail(t *testing.T) { t.Fatal(\"boom\") }\n"
+		}
+		pkgDir := filepath.Join(tmpDir, pkg)
+		require.NoError(t, os.MkdirAll(pkgDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(pkgDir, pkg+"_test.go"), []byte(content), 0644))
+	}
+
+	// every node runs concurrently and shares the queue only through the directory, as separate processes do
+	queueDir := filepath.Join(tmpDir, "queue")
+	const nodes = 3
+	errs := make([]error, nodes)
+	var wg sync.WaitGroup
+	for i := range nodes {
+		wg.Go(func() {
+			errs[i] = RunTestCmd(tmpDir, nil, nil, "", nil, TestParam{QueueDir: queueDir, QueueID: "run-1"}, &bytes.Buffer{})
+		})
+	}
+	wg.Wait()
+
+	// the node that tested the failing package reports its failure and the other nodes report the failure of the queue
+	var failedNodes int
+	for _, err := range errs {
+		require.Error(t, err)
+		if err.Error() == "1 package(s) had failing tests:\n\ttestmod/fail\n\t\ttestmod/fail.TestFail: fail/fail_test.go:7" {
+			failedNodes++
+			continue
+		}
+		assert.Regexp(t, `^1 package\(s\) of the queue in .+ failed on other nodes or were not tested:\n\ttestmod/fail \(.+\)$`, err.Error())
+	}
+	assert.Equal(t, 1, failedNodes)
+
+	content, err := os.ReadFile(filepath.Join(queueDir, queueSummaryFile))
+	require.NoError(t, err)
+	var summary queueResults
+	require.NoError(t, json.Unmarshal(content, &summary))
+	assert.Len(t, summary.Nodes, nodes)
+	var pkgs []string
+	for _, pkg := range summary.Packages {
+		pkgs = append(pkgs, pkg.Package)
+	}
+	assert.Equal(t, []string{"testmod/a", "testmod/b", "testmod/c", "testmod/d", "testmod/fail"}, pkgs)
+	assert.Equal(t, []string{"testmod/fail"}, summary.FailedPackages)
+	assert.Equal(t, []string{"TestFail"}, summary.Packages[4].FailedTests)
+
+	// a node that joins a completed queue reports the summary of the queue without testing any packages
+	stdout := &bytes.Buffer{}
+	err = RunTestCmd(tmpDir, nil, nil, "", nil, TestParam{QueueDir: queueDir, QueueID: "run-1"}, stdout)
+	assert.EqualError(t, err, "1 package(s) of the queue in "+queueDir+" failed on other nodes or were not tested:\n\ttestmod/fail ("+summary.Packages[4].Node+")")
+	assert.Contains(t, stdout.String(), "Queue summary: 5 package(s) tested by 3 node(s), 1 failed\n\ttestmod/fail (")
+	assert.NotContains(t, stdout.String(), "TestPass")
+
+	// a queue directory that is reused by a different run is reset rather than reporting the results of the previous
+	// run
+	stdout = &bytes.Buffer{}
+	err = RunTestCmd(tmpDir, nil, nil, "", nil, TestParam{QueueDir: queueDir, QueueID: "run-2"}, stdout)
+	assert.EqualError(t, err, "1 package(s) had failing tests:\n\ttestmod/fail\n\t\ttestmod/fail.TestFail: fail/fail_test.go:7")
+	assert.Contains(t, stdout.String(), "Queue summary: 5 package(s) tested by 1 node(s), 1 failed\n\ttestmod/fail (")
+	resultFiles, err := filepath.Glob(filepath.Join(queueDir, queueResultsDir, "*.json"))
+	require.NoError(t, err)
+	assert.Len(t, resultFiles, 1)
+
+	err = RunTestCmd(tmpDir, nil, nil, "", nil, TestParam{QueueDir: queueDir}, &bytes.Buffer{})
+	assert.EqualError(t, err, "a queue ID must be specified if a queue directory is specified")
+}
+
+func TestTestQueue(t *testing.T) {
+	queueDir := t.TempDir()
+	q, err := joinTestQueue(queueDir, "run-1", "testmod", []string{"./a", "./b"})
+	require.NoError(t, err)
+
+	_, err = joinTestQueue(queueDir, "run-1", "testmod", []string{"./a"})
+	assert.EqualError(t, err, "the packages to test do not match the packages of the queue in "+queueDir+", which was created by another node: all nodes must test the same packages")
+
+	// a queue that is still used by a different run cannot be joined
+	_, err = joinTestQueue(queueDir, "run-2", "testmod", []string{"./a", "./b"})
+	assert.EqualError(t, err, "the queue in "+queueDir+" is used by run \"run-1\", which has not completed: a queue directory can only be used by one run at a time")
+
+	// a lock left behind by a node that crashed is removed
+	lockFile := filepath.Join(queueDir, queueLockFileName)
+	require.NoError(t, os.WriteFile(lockFile, []byte("crashed-node-0000"), 0644))
+	staleTime := time.Now().Add(-2 * queueLockStaleAfter)
+	require.NoError(t, os.Chtimes(lockFile, staleTime, staleTime))
+
+	var claimed []string
+	for {
+		pkg, ok, err := q.claim()
+		require.NoError(t, err)
+		if !ok {
+			break
+		}
+		claimed = append(claimed, pkg)
+	}
+	assert.Equal(t, []string{"./a", "./b"}, claimed)
+	assert.NoFileExists(t, lockFile)
+}
+
+func TestTestQueueLockBroken(t *testing.T) {
+	queueDir := t.TempDir()
+	q, err := joinTestQueue(queueDir, "run-1", "testmod", []string{"./a"})
+	require.NoError(t, err)
+
+	// a node whose lock was broken because it held the lock for too long does not write the queue or remove the lock
+	// that is now held by another node
+	lockFile := filepath.Join(queueDir, queueLockFileName)
+	err = q.update(func(*queueState) error {
+		return os.WriteFile(lockFile, []byte("other-node-0000"), 0644)
+	})
+	assert.EqualError(t, err, "the queue lock "+lockFile+" was broken by another node because it was held for longer than 30s")
+	content, err := os.ReadFile(lockFile)
+	require.NoError(t, err)
+	assert.Equal(t, "other-node-0000", string(content))
+
+	// a lock that is not stale is not broken
+	require.NoError(t, breakStaleQueueLock(lockFile, "breaker-0000"))
+	assert.FileExists(t, lockFile)
+}
+
+func TestTestQueueLostNode(t *testing.T) {
+	queueDir := t.TempDir()
+	pkgs := []string{"./a", "./b", "./c"}
+	crashed, err := joinTestQueue(queueDir, "run-1", "testmod", pkgs)
+	require.NoError(t, err)
+	lost, err := joinTestQueue(queueDir, "run-1", "testmod", pkgs)
+	require.NoError(t, err)
+	live, err := joinTestQueue(queueDir, "run-1", "testmod", pkgs)
+	require.NoError(t, err)
+
+	claimAll := func(q *testQueue) []string {
+		var claimed []string
+		for {
+			pkg, ok, err := q.claim()
+			require.NoError(t, err)
+			if !ok {
+				return claimed
+			}
+			claimed = append(claimed, pkg)
+		}
+	}
+	pkg, ok, err := crashed.claim()
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "./a", pkg)
+
+	// the node that claimed "./a" crashed and the node that claims it again finishes without reporting its result
+	require.NoError(t, live.update(func(state *queueState) error {
+		n := state.Nodes[crashed.node]
+		n.LastSeen = time.Now().Add(-2 * queueNodeSilentAfter)
+		state.Nodes[crashed.node] = n
+		return nil
+	}))
+	pkg, ok, err = lost.claim()
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "./a", pkg)
+	require.NoError(t, lost.update(func(state *queueState) error {
+		n := state.Nodes[lost.node]
+		n.Finished = true
+		state.Nodes[lost.node] = n
+		return nil
+	}))
+
+	// the packages that were claimed by the node that crashed are re-queued
+	assert.Equal(t, []string{"./b", "./c"}, claimAll(live))
+	results := newTestResults()
+	for _, name := range []string{"testmod/b", "testmod/c"} {
+		results.process(testEvent{Action: actionStart, Package: name})
+		results.process(testEvent{Action: actionPass, Package: name})
+	}
+	// the node that writes the summary fails because a package was not tested
+	stdout := &bytes.Buffer{}
+	wantErr := "1 package(s) of the queue in " + queueDir + " failed on other nodes or were not tested:\n\ttestmod/a (claimed by node " + lost.node + ", which did not report its result)"
+	assert.EqualError(t, live.finish(results, stdout), wantErr)
+
+	summary, err := readQueueResults(filepath.Join(queueDir, queueSummaryFile))
+	require.NoError(t, err)
+	assert.Equal(t, []string{crashed.node}, summary.LostNodes)
+	assert.Equal(t, []string{"testmod/a"}, summary.FailedPackages)
+	assert.Equal(t, queuePkgResult{
+		Package: "testmod/a",
+		Node:    lost.node,
+		Status:  actionFail,
+		Error:   "claimed by node " + lost.node + ", which did not report its result",
+	}, summary.Packages[0])
+	assert.Equal(t, "Queue summary: 3 package(s) tested by 1 node(s), 1 failed\n\ttestmod/a (claimed by node "+lost.node+", which did not report its result)\n\t1 node(s) stopped responding: "+crashed.node+"\n", stdout.String())
+
+	// the node that crashed does not write the summary again if it finishes after the queue completed, and it fails
+	// as well
+	assert.EqualError(t, crashed.finish(newTestResults(), &bytes.Buffer{}), wantErr)
+	content, err := os.ReadFile(filepath.Join(queueDir, queueSummaryFile))
+	require.NoError(t, err)
+	var rewritten queueResults
+	require.NoError(t, json.Unmarshal(content, &rewritten))
+	assert.Equal(t, summary, rewritten)
+}
+
+func TestQueueIDFromEnv(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{name: "no environment variables"},
+		{name: "CI provider", env: map[string]string{"BUILDKITE_BUILD_ID": "build-1"}, want: "build-1"},
+		{name: "explicit ID takes precedence", env: map[string]string{QueueIDEnvVar: "run-1", "CI_PIPELINE_ID": "42"}, want: "run-1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, queueIDFromEnv(func(key string) (string, bool) {
+				val, ok := tc.env[key]
+				return val, ok
+			}))
+		})
+	}
+}
@@ -0,0 +1,473 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// testResults is the model of a test run that is built from the events emitted by "go test -json". The console
+// output, the failed packages and the JUnit report are all derived from this model.
+type testResults struct {
+	// pkgs contains the results for every package in the order in which the packages were first reported.
+	pkgs   []*pkgResult
+	byName map[string]*pkgResult
+	// buildOutput contains the "build-output" lines keyed by their ImportPath. The output is attached to a package
+	// when the package fails with a FailedBuild value that matches the key.
+	buildOutput map[string][]string
+}
+
+// pkgResult is the result of testing a single package.
+type pkgResult struct {
+	Name string
+	// Status is the action of the terminal event for the package ("pass", "fail" or "skip"). Empty if the package has
+	// not completed.
+	Status  string
+	Start   time.Time
+	Elapsed time.Duration
+	// Output contains the lines of output for the package that were not attributed to a specific test.
+	Output []string
+	// FailedBuild and BuildOutput are set if the package failed because of a build failure.
+	FailedBuild string
+	BuildOutput []string
+	// Tests contains the results of the tests in the package in the order in which they were started.
+	Tests []*testResult
+	// Hung is true if the package was still running when its command was considered to be hung (see markHung).
+	// GoroutineDump contains the output for the package that was not attributed to a specific test and that was
+	// written after that time, and GoroutineDumpFile is the file to which the goroutine dumps of the package were
+	// written.
+	Hung              bool
+	GoroutineDump     []string
+	GoroutineDumpFile string
+	// Interrupted is true if the package had not completed or had tests that had not completed when the run was
+	// interrupted by a signal.
+	Interrupted bool
+	byName      map[string]*testResult
+}
+
+// testResult is the result of a single test or subtest.
+type testResult struct {
+	// Name is the full name of the test, including the names of parent tests for subtests (for example,
+	// "TestFoo/bar").
+	Name string
+	// Status is the action of the terminal event for the test ("pass", "fail" or "skip"). Empty if the test has not
+	// completed.
+	Status  string
+	Elapsed time.Duration
+	Output  []string
+	// FailedAttempts contains the output of the earlier attempts of the test that failed if the test was retried.
+	FailedAttempts [][]string
+	// Hung is true if the test was still running when its command was considered to be hung (see markHung), in
+	// which case GoroutineDump contains the output of the test that was written after that time.
+	Hung          bool
+	GoroutineDump []string
+	// Interrupted is true if the test had not completed when the run was interrupted by a signal.
+	Interrupted bool
+}
+
+// isFlaky returns true if the test failed and then passed when it was retried.
+func (t *testResult) isFlaky() bool {
+	return t.Status == actionPass && len(t.FailedAttempts) > 0
+}
+
+// isTopLevel returns true if the test is not a subtest.
+func (t *testResult) isTopLevel() bool {
+	return !strings.Contains(t.Name, "/")
+}
+
+func newTestResults() *testResults {
+	return &testResults{
+		byName:      make(map[string]*pkgResult),
+		buildOutput: make(map[string][]string),
+	}
+}
+
+// process records the provided event in the model.
+func (r *testResults) process(ev testEvent) {
+	switch ev.Action {
+	case actionBuildOutput:
+		r.buildOutput[ev.ImportPath] = append(r.buildOutput[ev.ImportPath], strings.TrimSuffix(ev.Output, "\n"))
+		return
+	case actionBuildFail:
+		return
+	}
+	if ev.Package == "" {
+		return
+	}
+
+	pkg := r.pkg(ev.Package)
+	if ev.Test == "" {
+		switch {
+		case ev.Action == actionStart:
+			pkg.Start = ev.Time
+		case ev.Action == actionOutput && pkg.Hung:
+			pkg.GoroutineDump = append(pkg.GoroutineDump, strings.TrimSuffix(ev.Output, "\n"))
+		case ev.Action == actionOutput:
+			pkg.Output = append(pkg.Output, strings.TrimSuffix(ev.Output, "\n"))
+		case ev.isTerminal():
+			pkg.Status = ev.Action
+			pkg.Elapsed = ev.elapsed()
+			if ev.FailedBuild != "" {
+				pkg.FailedBuild = ev.FailedBuild
+				pkg.BuildOutput = r.buildOutput[ev.FailedBuild]
+			}
+		}
+		return
+	}
+
+	test := pkg.test(ev.Test)
+	switch {
+	case ev.Action == actionOutput && test.Hung:
+		test.GoroutineDump = append(test.GoroutineDump, strings.TrimSuffix(ev.Output, "\n"))
+	case ev.Action == actionOutput:
+		test.Output = append(test.Output, strings.TrimSuffix(ev.Output, "\n"))
+	case ev.isTerminal():
+		test.Status = ev.Action
+		test.Elapsed = ev.elapsed()
+	}
+}
+
+// pkg returns the result for the package with the provided name, creating it if it does not exist.
+func (r *testResults) pkg(name string) *pkgResult {
+	if pkg, ok := r.byName[name]; ok {
+		return pkg
+	}
+	pkg := &pkgResult{
+		Name:   name,
+		byName: make(map[string]*testResult),
+	}
+	r.pkgs = append(r.pkgs, pkg)
+	r.byName[name] = pkg
+	return pkg
+}
+
+// markHung marks the packages and tests that are running (that have started but not completed) as hung. The output
+// that is recorded for them after this is called is recorded as their goroutine dumps. Returns the packages and tests
+// that were marked in the form "pkg" or "pkg.TestName".
+func (r *testResults) markHung() []string {
+	var running []string
+	for _, pkg := range r.pkgs {
+		if pkg.Status != "" {
+			continue
+		}
+		pkg.Hung = true
+		var runningTests []string
+		for _, test := range pkg.Tests {
+			if test.Status == "" {
+				test.Hung = true
+				runningTests = append(runningTests, pkg.Name+"."+test.Name)
+			}
+		}
+		if len(runningTests) == 0 {
+			runningTests = []string{pkg.Name}
+		}
+		running = append(running, runningTests...)
+	}
+	return running
+}
+
+// markInterrupted marks the packages and tests that have not completed as interrupted. Tests that hung are not marked.
+func (r *testResults) markInterrupted() {
+	for _, pkg := range r.pkgs {
+		pkg.Interrupted = pkg.Interrupted || (pkg.Status == "" && !pkg.Hung)
+		for _, test := range pkg.Tests {
+			if test.Status == "" && !test.Hung {
+				test.Interrupted = true
+				pkg.Interrupted = true
+			}
+		}
+	}
+}
+
+// interruptedPkgs returns the names of the packages that were interrupted in the order in which they were reported.
+func (r *testResults) interruptedPkgs() []string {
+	var interrupted []string
+	for _, pkg := range r.pkgs {
+		if pkg.Interrupted {
+			interrupted = append(interrupted, pkg.Name)
+		}
+	}
+	return interrupted
+}
+
+// hungPkgs returns the names of the packages that were hung in the order in which they were reported.
+func (r *testResults) hungPkgs() []string {
+	var hung []string
+	for _, pkg := range r.pkgs {
+		if pkg.Hung {
+			hung = append(hung, pkg.Name)
+		}
+	}
+	return hung
+}
+
+// failedPkgs returns the names of the packages that failed in the order in which they were reported. Packages that hung
+// are considered to have failed even if they did not complete.
+func (r *testResults) failedPkgs() []string {
+	var failed []string
+	for _, pkg := range r.pkgs {
+		if pkg.Status == actionFail || pkg.Hung {
+			failed = append(failed, pkg.Name)
+		}
+	}
+	return failed
+}
+
+// retryableTests returns the names of the top-level tests that failed or did not complete, keyed by package. Packages
+// that failed to build or that failed without a failing test are not included because re-running specific tests
+// cannot fix them, and packages that hung are not included because re-running them is likely to hang again.
+func (r *testResults) retryableTests() map[string][]string {
+	retryable := make(map[string][]string)
+	for _, pkg := range r.pkgs {
+		if pkg.Status != actionFail || pkg.FailedBuild != "" || pkg.Hung {
+			continue
+		}
+		if failedTests := pkg.failedTests(); len(failedTests) > 0 {
+			retryable[pkg.Name] = failedTests
+		}
+	}
+	return retryable
+}
+
+// mergeRetry merges the results of re-running the provided tests into the receiver. The result of every test that
+// was re-run is replaced by its result in "retry" and the output of any earlier attempt that failed is retained in
+// FailedAttempts. The status of every package that was re-run is replaced by its status in "retry", except that a
+// package that failed outside of its tests or that still has failing tests continues to fail even if the tests that
+// were re-run passed, since the retry only runs those tests.
+func (r *testResults) mergeRetry(retry *testResults, retried map[string][]string) {
+	for pkgName, tests := range retried {
+		pkg, retryPkg := r.byName[pkgName], retry.byName[pkgName]
+		if pkg == nil || retryPkg == nil {
+			continue
+		}
+		failedOutsideTests := pkg.failedOutsideTests()
+		for _, retryTest := range retryPkg.Tests {
+			if !slices.Contains(tests, topLevelTestName(retryTest.Name)) {
+				continue
+			}
+			test := pkg.test(retryTest.Name)
+			if test.Status != actionPass && test.Status != actionSkip {
+				test.FailedAttempts = append(test.FailedAttempts, test.Output)
+			}
+			test.Status = retryTest.Status
+			test.Elapsed = retryTest.Elapsed
+			test.Output = retryTest.Output
+		}
+		switch {
+		case retryPkg.Status == "":
+		case retryPkg.Status == actionPass && (failedOutsideTests || len(pkg.failedTests()) > 0):
+			pkg.Status = actionFail
+		default:
+			pkg.Status = retryPkg.Status
+		}
+		pkg.Elapsed += retryPkg.Elapsed
+	}
+}
+
+// merge adds the results in "other", which were recorded from a different command, to the receiver. If a package is
+// in both, the output and tests of the package in "other" are added to it and it fails if it failed in either.
+func (r *testResults) merge(other *testResults) {
+	for importPath, output := range other.buildOutput {
+		r.buildOutput[importPath] = append(r.buildOutput[importPath], output...)
+	}
+	for _, otherPkg := range other.pkgs {
+		pkg, ok := r.byName[otherPkg.Name]
+		if !ok {
+			r.pkgs = append(r.pkgs, otherPkg)
+			r.byName[otherPkg.Name] = otherPkg
+			continue
+		}
+		pkg.Output = append(pkg.Output, otherPkg.Output...)
+		for _, test := range otherPkg.Tests {
+			if _, ok := pkg.byName[test.Name]; !ok {
+				pkg.Tests = append(pkg.Tests, test)
+				pkg.byName[test.Name] = test
+			}
+		}
+		if pkg.Status == "" || otherPkg.Status == actionFail || pkg.Status == actionSkip {
+			pkg.Status = otherPkg.Status
+		}
+		pkg.Interrupted = pkg.Interrupted || otherPkg.Interrupted
+		if otherPkg.Hung {
+			pkg.Hung = true
+			pkg.GoroutineDump = append(pkg.GoroutineDump, otherPkg.GoroutineDump...)
+		}
+		if otherPkg.FailedBuild != "" {
+			pkg.FailedBuild = otherPkg.FailedBuild
+			pkg.BuildOutput = otherPkg.BuildOutput
+		}
+		if pkg.Start.IsZero() || (!otherPkg.Start.IsZero() && otherPkg.Start.Before(pkg.Start)) {
+			pkg.Start = otherPkg.Start
+		}
+		pkg.Elapsed += otherPkg.Elapsed
+	}
+}
+
+// flakyTests returns the top-level tests that failed and then passed when they were retried in the form
+// "pkg.TestName".
+func (r *testResults) flakyTests() []string {
+	var flaky []string
+	for _, pkg := range r.pkgs {
+		for _, test := range pkg.Tests {
+			if test.isTopLevel() && test.isFlaky() {
+				flaky = append(flaky, pkg.Name+"."+test.Name)
+			}
+		}
+	}
+	return flaky
+}
+
+// failedTests returns the names of the top-level tests in the package that failed or did not complete.
+func (p *pkgResult) failedTests() []string {
+	var failed []string
+	for _, test := range p.Tests {
+		if test.isTopLevel() && test.Status != actionPass && test.Status != actionSkip {
+			failed = append(failed, test.Name)
+		}
+	}
+	return failed
+}
+
+// interruptedTests returns the names of the tests in the package that were interrupted.
+func (p *pkgResult) interruptedTests() []string {
+	var interrupted []string
+	for _, test := range p.Tests {
+		if test.Interrupted {
+			interrupted = append(interrupted, test.Name)
+		}
+	}
+	return interrupted
+}
+
+// hungTests returns the names of the tests in the package that hung.
+func (p *pkgResult) hungTests() []string {
+	var hung []string
+	for _, test := range p.Tests {
+		if test.Hung {
+			hung = append(hung, test.Name)
+		}
+	}
+	return hung
+}
+
+// The kinds of failures that prevent the tests of a package from running (see buildFailure).
+const (
+	// buildFailureCompile is a failure to compile the package, its tests or one of its dependencies.
+	buildFailureCompile = "build"
+	// buildFailureVet is a failure of the "go vet" checks that "go test" runs before the tests.
+	buildFailureVet = "vet"
+	// buildFailureSetup is a failure to load the package or its dependencies (for example, because an imported package
+	// does not exist).
+	buildFailureSetup = "setup"
+)
+
+// buildFailure returns the kind of failure that prevented the tests of the package from running, or an empty string
+// if the tests ran.
+func (p *pkgResult) buildFailure() string {
+	switch {
+	case slices.ContainsFunc(p.Output, func(line string) bool {
+		return strings.HasPrefix(line, "FAIL") && strings.HasSuffix(line, "[setup failed]")
+	}):
+		return buildFailureSetup
+	case p.FailedBuild == "":
+		return ""
+	case slices.ContainsFunc(p.BuildOutput, func(line string) bool {
+		// the output of "go vet" is preceded by the package in brackets (for example, "# [github.com/org/pkg]")
+		return strings.HasPrefix(line, "# [")
+	}):
+		return buildFailureVet
+	default:
+		return buildFailureCompile
+	}
+}
+
+// failedDependency returns the import path of the dependency that failed to build if the package failed to build
+// because one of its dependencies failed to build, or an empty string otherwise.
+func (p *pkgResult) failedDependency() string {
+	if p.FailedBuild == "" {
+		return ""
+	}
+	// FailedBuild is the import path of the package that failed to build, which is followed by the test binary that
+	// it was built for (for example, "github.com/org/pkg [github.com/org/pkg.test]") if the package was recompiled
+	// for the tests
+	failedPkg, _, _ := strings.Cut(p.FailedBuild, " ")
+	if failedPkg == p.Name {
+		return ""
+	}
+	return failedPkg
+}
+
+// failedOutsideTests returns true if the package failed and its output that is not attributed to any test contains
+// lines other than the result lines written by the testing framework and the go command (for example, the output of a
+// TestMain or init function that failed or of a panic outside of any test).
+func (p *pkgResult) failedOutsideTests() bool {
+	return p.Status == actionFail && slices.ContainsFunc(p.Output, func(line string) bool {
+		return !isPkgResultLine(line)
+	})
+}
+
+// isPkgResultLine returns true if the provided line of the output of a package that is not attributed to any test is
+// written by the testing framework or the go command to report the result of the package.
+func isPkgResultLine(line string) bool {
+	switch trimmed := strings.TrimSpace(line); {
+	case trimmed == "", trimmed == "PASS", trimmed == "FAIL", isFrameLine(line):
+		return true
+	case strings.HasPrefix(line, "ok  \t"), strings.HasPrefix(line, "FAIL\t"), strings.HasPrefix(line, "?   \t"):
+		return true
+	case strings.HasPrefix(line, "exit status "), strings.HasPrefix(line, "coverage: "), strings.HasPrefix(line, "\t"):
+		// a line that starts with a tab is the summary line of a package without test files when coverage is
+		// collected
+		return true
+	case strings.HasPrefix(line, "testing: warning: no tests to run"):
+		return true
+	}
+	return false
+}
+
+// cached returns true if the package passed and its result was reported from the cache of the go command.
+func (p *pkgResult) cached() bool {
+	return p.Status == actionPass && slices.ContainsFunc(p.Output, func(line string) bool {
+		// the summary line ends with the coverage of the package if coverage is collected
+		return strings.HasPrefix(line, "ok") && (strings.HasSuffix(line, "(cached)") || strings.Contains(line, "\t(cached)\t"))
+	})
+}
+
+// noTestFiles returns true if the package was skipped because it does not contain any test files. If coverage is
+// collected, a package without test files passes instead and its summary line only contains its coverage.
+func (p *pkgResult) noTestFiles() bool {
+	if p.Status == actionPass && len(p.Tests) == 0 {
+		return slices.ContainsFunc(p.Output, func(line string) bool {
+			return strings.HasPrefix(line, "\t"+p.Name+"\t\tcoverage: ")
+		})
+	}
+	return p.Status == actionSkip && slices.ContainsFunc(p.Output, func(line string) bool {
+		return strings.HasSuffix(line, "[no test files]")
+	})
+}
+
+// test returns the result for the test with the provided name, creating it if it does not exist.
+func (p *pkgResult) test(name string) *testResult {
+	if test, ok := p.byName[name]; ok {
+		return test
+	}
+	test := &testResult{
+		Name: name,
+	}
+	p.Tests = append(p.Tests, test)
+	p.byName[name] = test
+	return test
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	goerrors "errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// retryFailedTests re-runs the top-level tests that failed in "results" up to "retries" times and merges the results
+// of every attempt into "results". Every attempt only re-runs the tests that failed in the previous attempt, and the
+// retries stop as soon as no retryable tests remain. Returns an error only if a "go test" command could not be run:
+// test failures are recorded in "results".
+func retryFailedTests(projectDir string, testArgs []string, retries int, results *testResults, stdout io.Writer, opts execOptions) error {
+	for attempt := 1; attempt <= retries; attempt++ {
+		retryable := results.retryableTests()
+		if len(retryable) == 0 {
+			return nil
+		}
+		pkgs := make([]string, 0, len(retryable))
+		var tests []string
+		for pkg, pkgTests := range retryable {
+			pkgs = append(pkgs, pkg)
+			tests = append(tests, pkgTests...)
+		}
+		slices.Sort(pkgs)
+
+		_, _ = fmt.Fprintf(stdout, "Retrying %d failed test(s) in %d package(s) (attempt %d of %d)\n", len(tests), len(pkgs), attempt, retries)
+		// the tests of all the packages are re-run in a single command: a test with the same name as a failed test in
+		// another package may also be run, but only the results of the tests that failed are merged
+		cmd := goTestCmd(projectDir, append(slices.Clone(testArgs), "-run", runRegexp(tests)), pkgs)
+		retryResults := newTestResults()
+		if err := executeTestCmd(cmd, stdout, retryResults, opts); err != nil {
+			if _, ok := goerrors.AsType[*exec.ExitError](err); !ok {
+				return err
+			}
+		}
+		results.mergeRetry(retryResults, retryable)
+	}
+	return nil
+}
+
+// runRegexp returns a regular expression for the "-run" flag of "go test" that matches exactly the provided top-level
+// tests (and all of their subtests).
+func runRegexp(tests []string) string {
+	quoted := make([]string, 0, len(tests))
+	for _, test := range slices.Compact(slices.Sorted(slices.Values(tests))) {
+		quoted = append(quoted, regexp.QuoteMeta(test))
+	}
+	return "^(" + strings.Join(quoted, "|") + ")$"
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunRegexp(t *testing.T) {
+	assert.Equal(t, "^(TestFoo)$", runRegexp([]string{"TestFoo"}))
+	assert.Equal(t, `^(TestBar|TestFoo|Test_Baz\.)$`, runRegexp([]string{"TestFoo", "Test_Baz.", "TestBar", "TestFoo"}))
+}
+
+func TestMergeRetry(t *testing.T) {
+	results := newTestResults()
+	for _, ev := range []testEvent{
+		{Action: actionRun, Package: "pkg", Test: "TestFlaky"},
+		{Action: actionOutput, Package: "pkg", Test: "TestFlaky", Output: "    pkg_test.go:10: failed\n"},
+		{Action: actionFail, Package: "pkg", Test: "TestFlaky"},
+		{Action: actionRun, Package: "pkg", Test: "TestBroken"},
+		{Action: actionFail, Package: "pkg", Test: "TestBroken"},
+		{Action: actionRun, Package: "pkg", Test: "TestPass"},
+		{Action: actionPass, Package: "pkg", Test: "TestPass"},
+		{Action: actionFail, Package: "pkg"},
+	} {
+		results.process(ev)
+	}
+	retryable := results.retryableTests()
+	assert.Equal(t, map[string][]string{"pkg": {"TestFlaky", "TestBroken"}}, retryable)
+
+	retry := newTestResults()
+	for _, ev := range []testEvent{
+		{Action: actionRun, Package: "pkg", Test: "TestFlaky"},
+		{Action: actionPass, Package: "pkg", Test: "TestFlaky"},
+		{Action: actionRun, Package: "pkg", Test: "TestBroken"},
+		{Action: actionFail, Package: "pkg", Test: "TestBroken"},
+		{Action: actionFail, Package: "pkg"},
+	} {
+		retry.process(ev)
+	}
+	results.mergeRetry(retry, retryable)
+
+	assert.Equal(t, []string{"pkg.TestFlaky"}, results.flakyTests())
+	assert.Equal(t, [][]string{{"    pkg_test.go:10: failed"}}, results.byName["pkg"].byName["TestFlaky"].FailedAttempts)
+	assert.Equal(t, []string{"TestBroken"}, results.byName["pkg"].failedTests())
+	assert.Equal(t, []string{"pkg"}, results.failedPkgs())
+}
+
+func TestMergeRetryPkgFailure(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		pkgOutput []string
+		wantFail  bool
+	}{
+		{
+			name:      "package passes if the retried tests pass",
+			pkgOutput: []string{"FAIL\n", "exit status 1\n", "FAIL\tpkg\t0.010s\n"},
+		},
+		{
+			name:      "package that failed outside of its tests still fails",
+			pkgOutput: []string{"TestMain: failed to start fixtures\n", "FAIL\n", "exit status 1\n", "FAIL\tpkg\t0.010s\n"},
+			wantFail:  true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			results := newTestResults()
+			events := []testEvent{
+				{Action: actionRun, Package: "pkg", Test: "TestFlaky"},
+				{Action: actionFail, Package: "pkg", Test: "TestFlaky"},
+			}
+			for _, output := range tc.pkgOutput {
+				events = append(events, testEvent{Action: actionOutput, Package: "pkg", Output: output})
+			}
+			for _, ev := range append(events, testEvent{Action: actionFail, Package: "pkg"}) {
+				results.process(ev)
+			}
+			retryable := results.retryableTests()
+			assert.Equal(t, map[string][]string{"pkg": {"TestFlaky"}}, retryable)
+
+			retry := newTestResults()
+			for _, ev := range []testEvent{
+				{Action: actionRun, Package: "pkg", Test: "TestFlaky"},
+				{Action: actionPass, Package: "pkg", Test: "TestFlaky"},
+				{Action: actionPass, Package: "pkg"},
+			} {
+				retry.process(ev)
+			}
+			results.mergeRetry(retry, retryable)
+
+			assert.Equal(t, []string{"pkg.TestFlaky"}, results.flakyTests())
+			if tc.wantFail {
+				assert.Equal(t, []string{"pkg"}, results.failedPkgs())
+			} else {
+				assert.Empty(t, results.failedPkgs())
+			}
+		})
+	}
+}
@@ -15,17 +15,24 @@
 package testplugin
 
 import (
-	"bufio"
 	"bytes"
+	"fmt"
 	"io"
+	"maps"
+	"os"
 	"os/exec"
-	"regexp"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
 func longestPkgNameLen(pkgPaths []string, projectDir string) (int, error) {
+	if len(pkgPaths) == 0 {
+		return 0, nil
+	}
 	goListCmd := exec.Command("go", append([]string{"list"}, pkgPaths...)...)
 	goListCmd.Dir = projectDir
 
@@ -44,62 +51,134 @@ func longestPkgNameLen(pkgPaths []string, projectDir string) (int, error) {
 	return longestPkgLen, nil
 }
 
-// executeTestCommand executes the provided command. The output produced by the command's Stdout and Stderr calls are
-// processed as they are written and an aligned version of the output is written to the Stdout of the current process.
-// The "longestPkgNameLen" parameter specifies the longest package name (used to align the console output). This
-// function returns a slice that contains the packages that had test failures (output line started with "FAIL"). The
-// error value will contain any error that was encountered while executing the command, including if the command
-// executed successfully but any tests failed. In either case, the packages that encountered errors will also be
-// returned.
-func executeTestCmd(execCmd *exec.Cmd, stdout, rawOutputWriter io.Writer, longestPkgNameLen int) (rFailedPkgs []string, rErr error) {
-	bw := bufio.NewWriter(rawOutputWriter)
-
-	// stream output to Stdout
-	multiWriter := multiWriter{
-		consoleWriter:     stdout,
-		rawOutputWriter:   bw,
-		failedPkgs:        []string{},
-		longestPkgNameLen: longestPkgNameLen,
-	}
-
-	// flush buffered writer at the end of the function
-	defer func() {
-		if err := bw.Flush(); err != nil && rErr == nil {
-			rErr = errors.Wrapf(err, "failed to flush buffered writer in defer")
-		}
-	}()
-
-	// set Stdout and Stderr of command to multiwriter
-	execCmd.Stdout = &multiWriter
-	execCmd.Stderr = &multiWriter
+// execOptions are the options for executing "go test -json" commands.
+type execOptions struct {
+	// verbose determines whether the output of tests that pass is printed to the console.
+	verbose   bool
+	maxPkgLen int
+	// inactivityTimeout is the duration without any output from a command after which the command is considered to
+	// be hung (see watchInactivity). Disabled if 0.
+	inactivityTimeout time.Duration
+	// interrupts forwards the signals received by the plugin to the commands. May be nil.
+	interrupts *interruptHandler
+	// failFast aborts all of the commands using "interrupts" as soon as any package fails.
+	failFast bool
+	// progress reports the progress of the packages. May be nil.
+	progress *progressReporter
+	// colors colors the console output.
+	colors colors
+	// coverage collects the coverage profiles of the commands. May be nil.
+	coverage *coverageCollector
+}
 
-	// run command (which will print its Stdout and Stderr to the Stdout of current process) and return output
-	err := execCmd.Run()
+// hungKillGracePeriod is the duration for which a hung command is given to write goroutine dumps and exit after it is
+// sent SIGQUIT before it is killed.
+const hungKillGracePeriod = 30 * time.Second
+
+// executeTestCmd executes the provided "go test -json" command. The events written to the command's Stdout are decoded
+// as they are written and recorded in "results", and a human-readable version of the output is written to "stdout"
+// (see consolePrinter). Content written to the command's Stderr and any lines written to its Stdout that are not JSON
+// events are written to "stdout" unmodified. The returned error is the error encountered while executing the command,
+// which includes the case where the command executed successfully but any tests failed: the packages that failed
+// should be determined using "results".
+func executeTestCmd(execCmd *exec.Cmd, stdout io.Writer, results *testResults, opts execOptions) error {
+	w := &eventWriter{
+		results:    results,
+		console:    newConsolePrinter(stdout, opts.verbose, opts.maxPkgLen, opts.colors),
+		lastOutput: time.Now(),
+		progress:   opts.progress,
+	}
+	if opts.failFast {
+		w.onPkgFail = opts.interrupts.abort
+	}
+	execCmd.Stdout = w
+	execCmd.Stderr = stderrWriter{w: w}
+	// the command runs in its own process group so that the test binaries that it starts can be signaled without
+	// signaling the plugin
+	setProcessGroup(execCmd)
+	profile := opts.coverage.addProfileFlag(execCmd)
+
+	if err := execCmd.Start(); err != nil {
+		return err
+	}
+	opts.interrupts.add(execCmd.Process)
+	done := make(chan struct{})
+	if opts.inactivityTimeout > 0 {
+		go w.watchInactivity(execCmd.Process, opts.inactivityTimeout, done)
+	}
+	err := execCmd.Wait()
+	close(done)
+	opts.interrupts.remove(execCmd.Process)
 	// process any trailing output that was not terminated by a newline
-	if flushErr := multiWriter.Flush(); flushErr != nil && err == nil {
+	if flushErr := w.Flush(); flushErr != nil && err == nil {
 		err = flushErr
 	}
-	return multiWriter.failedPkgs, err
+	if opts.interrupts.stopped() {
+		results.markInterrupted()
+	}
+	opts.coverage.recordPkgs(profile, slices.Sorted(maps.Keys(w.pkgs)))
+	return err
+}
+
+// watchInactivity sends SIGQUIT to the process group of the provided process if the process does not write any output
+// for the provided duration, which causes the test binaries that it runs to write goroutine dumps and exit. The
+// packages and tests that are running at that time are marked as hung in the results, and the output written after
+// that time is recorded as their goroutine dumps. If the process has not exited after hungKillGracePeriod, its process
+// group is killed. Returns when "done" is closed.
+func (w *eventWriter) watchInactivity(process *os.Process, timeout time.Duration, done <-chan struct{}) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-timer.C:
+		}
+		w.mu.Lock()
+		if idle := time.Since(w.lastOutput); idle < timeout {
+			w.mu.Unlock()
+			timer.Reset(timeout - idle)
+			continue
+		}
+		running := w.results.markHung()
+		_, _ = fmt.Fprintf(w.console.out, "No output for %v: sending SIGQUIT to capture goroutine dumps (running: %s)\n", timeout, strings.Join(running, ", "))
+		w.mu.Unlock()
+		_ = quitProcessGroup(process)
+
+		select {
+		case <-done:
+		case <-time.After(hungKillGracePeriod):
+			_ = killProcessGroup(process)
+		}
+		return
+	}
 }
 
-type multiWriter struct {
-	consoleWriter     io.Writer
-	rawOutputWriter   io.Writer
-	failedPkgs        []string
-	longestPkgNameLen int
+// eventWriter is the writer for the Stdout of a "go test -json" command. Every complete line that is written to it is
+// decoded as an event, recorded in the results and printed to the console.
+type eventWriter struct {
+	// mu serializes the handling of the content written to the Stdout and Stderr of the command, which may be written
+	// concurrently.
+	mu      sync.Mutex
+	results *testResults
+	console *consolePrinter
+	// lastOutput is the time at which the command last wrote any output.
+	lastOutput time.Time
+	// onPkgFail, if non-nil, is called when a package fails.
+	onPkgFail func()
+	// progress records the start and completion of packages. May be nil.
+	progress *progressReporter
+	// pkgs contains the packages for which the command wrote events.
+	pkgs map[string]struct{}
 	// partial line from the end of the previous Write call. The writes performed by the command are
 	// not guaranteed to be line-aligned, so a line may be split across multiple Write calls.
 	pendingLine []byte
 }
 
-var setupFailedRegexp = regexp.MustCompile(`(^FAIL\t.+) (\[setup failed\]$)`)
-
-func (w *multiWriter) Write(p []byte) (int, error) {
-	// write unaltered output to file
-	n, err := w.rawOutputWriter.Write(p)
-	if err != nil {
-		return n, err
-	}
+func (w *eventWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastOutput = time.Now()
 
 	// only process complete lines: buffer any trailing partial line and prepend it to the content of
 	// the next Write call so that a line that is split across Write calls is still processed as a
@@ -109,73 +188,61 @@ func (w *multiWriter) Write(p []byte) (int, error) {
 	if lastNewlineIdx == -1 {
 		// no complete line is available: buffer all content and wait for more
 		w.pendingLine = buf
-		return n, err
+		return len(p), nil
 	}
 	// copy the remainder because "p" must not be retained after Write returns
 	w.pendingLine = append([]byte(nil), buf[lastNewlineIdx+1:]...)
 
-	if _, err := w.consoleWriter.Write([]byte(w.processLines(string(buf[:lastNewlineIdx+1])))); err != nil {
-		return n, err
+	for line := range strings.Lines(string(buf[:lastNewlineIdx+1])) {
+		if err := w.processLine(line); err != nil {
+			return len(p), err
+		}
 	}
-
-	// n and err are from the unaltered write to the rawOutputWriter
-	return n, err
+	return len(p), nil
 }
 
-// Flush processes and writes any buffered content that was not terminated by a newline. It must be
-// called after the command that writes to this writer has completed.
-func (w *multiWriter) Flush() error {
+// Flush processes any buffered content that was not terminated by a newline. It must be called after the command
+// that writes to this writer has completed.
+func (w *eventWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
 	if len(w.pendingLine) == 0 {
 		return nil
 	}
 	pendingLine := string(w.pendingLine)
 	w.pendingLine = nil
-	_, err := w.consoleWriter.Write([]byte(w.processLines(pendingLine)))
-	return err
+	return w.processLine(pendingLine)
 }
 
-// processLines aligns the package summary lines in the provided content and records the packages
-// that had failures in "failedPkgs". Returns the content with the summary lines aligned.
-func (w *multiWriter) processLines(content string) string {
-	lines := strings.Split(content, "\n")
-	for i, currLine := range lines {
-		// test output for valid case always starts with "Ok" or "FAIL"
-		if strings.HasPrefix(currLine, "ok") || strings.HasPrefix(currLine, "FAIL") || strings.HasPrefix(currLine, "?") {
-			if setupFailedRegexp.MatchString(currLine) {
-				// if line matches "setup failed" output, modify output to conform to expected style
-				// (namely, replace space between package name and "[setup failed]" with a tab)
-				currLine = setupFailedRegexp.ReplaceAllString(currLine, "$1\t$2")
-			}
-
-			// split into at most 4 parts
-			fields := strings.SplitN(currLine, "\t", 4)
-
-			// valid test lines have at least 3 parts: "[ok|FAIL|?]\t[pkgName]\t[time|no test files]"
-			if len(fields) >= 3 {
-				currPkgName := strings.TrimSpace(fields[1])
-				lines[i] = alignLine(fields, w.longestPkgNameLen)
-				// append package name to failures list if this was a failure
-				if strings.HasPrefix(currLine, "FAIL") {
-					w.failedPkgs = append(w.failedPkgs, currPkgName)
-				}
-			}
+func (w *eventWriter) processLine(line string) error {
+	ev, ok := parseTestEvent(line)
+	if !ok {
+		return w.console.printRaw(line)
+	}
+	w.results.process(ev)
+	w.progress.event(ev)
+	if ev.Package != "" {
+		if w.pkgs == nil {
+			w.pkgs = make(map[string]struct{})
 		}
+		w.pkgs[ev.Package] = struct{}{}
+	}
+	if w.onPkgFail != nil && ev.Package != "" && ev.Test == "" && ev.Action == actionFail {
+		w.onPkgFail()
 	}
+	return w.console.printEvent(ev)
+}
 
-	return strings.Join(lines, "\n")
+// stderrWriter is the writer for the Stderr of a "go test -json" command. Its content is written to the console
+// unmodified.
+type stderrWriter struct {
+	w *eventWriter
 }
 
-// alignLine returns a string where the length of the second field (fields[1]) is padded with spaces to make its length
-// equal to the value of maxPkgLen and the fields are joined with tab characters. Assuming that the first field is
-// always the same length, this method ensures that the third field will always be aligned together for any fixed value
-// of maxPkgLen.
-func alignLine(fields []string, maxPkgLen int) string {
-	currPkgName := fields[1]
-	repeat := max(maxPkgLen-len(currPkgName),
-		// this should not occur under normal circumstances. However, it appears that it is possible if tests
-		// create test packages in the directory structure while tests are already running. If such a case is
-		// encountered, having output that isn't aligned optimally is better than crashing, so set repeat to 0.
-		0)
-	fields[1] = currPkgName + strings.Repeat(" ", repeat)
-	return strings.Join(fields, "\t")
+func (s stderrWriter) Write(p []byte) (int, error) {
+	s.w.mu.Lock()
+	defer s.w.mu.Unlock()
+	s.w.lastOutput = time.Now()
+	return s.w.console.out.Write(p)
 }
@@ -16,19 +16,40 @@ package testplugin
 
 import (
 	"bytes"
-	"io"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-// TestMultiWriterFailedPkgs verifies that the packages that had failures are determined correctly
+// TestEventWriterFailedPkgs verifies that the packages that had failures are determined correctly
 // even if the content written to the writer is not line-aligned (the writes performed by the "go
-// test" command are not guaranteed to end on a line boundary).
-func TestMultiWriterFailedPkgs(t *testing.T) {
-	const output = `--- FAIL: TestFoo (0.00s)
+// test" command are not guaranteed to end on a line boundary) and even if the output of a test
+// contains lines that look like package summary lines.
+func TestEventWriterFailedPkgs(t *testing.T) {
+	const output = `{"Action":"start","Package":"github.com/palantir/project/pkgone"}
+{"Action":"run","Package":"github.com/palantir/project/pkgone","Test":"TestFoo"}
+{"Action":"output","Package":"github.com/palantir/project/pkgone","Test":"TestFoo","Output":"=== RUN   TestFoo\n"}
+{"Action":"output","Package":"github.com/palantir/project/pkgone","Test":"TestFoo","Output":"FAIL\tnot/a/package\t0.001s\n"}
+{"Action":"output","Package":"github.com/palantir/project/pkgone","Test":"TestFoo","Output":"    foo_test.go:13: assertion failed\n"}
+{"Action":"output","Package":"github.com/palantir/project/pkgone","Test":"TestFoo","Output":"--- FAIL: TestFoo (0.00s)\n"}
+{"Action":"fail","Package":"github.com/palantir/project/pkgone","Test":"TestFoo","Elapsed":0}
+{"Action":"output","Package":"github.com/palantir/project/pkgone","Output":"FAIL\n"}
+{"Action":"output","Package":"github.com/palantir/project/pkgone","Output":"FAIL\tgithub.com/palantir/project/pkgone\t0.021s\n"}
+{"Action":"fail","Package":"github.com/palantir/project/pkgone","Elapsed":0.021}
+{"Action":"start","Package":"github.com/palantir/project/pkgtwo"}
+{"Action":"run","Package":"github.com/palantir/project/pkgtwo","Test":"TestBar"}
+{"Action":"output","Package":"github.com/palantir/project/pkgtwo","Test":"TestBar","Output":"=== RUN   TestBar\n"}
+{"Action":"output","Package":"github.com/palantir/project/pkgtwo","Test":"TestBar","Output":"--- PASS: TestBar (0.00s)\n"}
+{"Action":"pass","Package":"github.com/palantir/project/pkgtwo","Test":"TestBar","Elapsed":0}
+{"Action":"output","Package":"github.com/palantir/project/pkgtwo","Output":"PASS\n"}
+{"Action":"output","Package":"github.com/palantir/project/pkgtwo","Output":"ok  \tgithub.com/palantir/project/pkgtwo\t0.004s\n"}
+{"Action":"pass","Package":"github.com/palantir/project/pkgtwo","Elapsed":0.004}
+FAIL
+`
+	const wantConsole = `FAIL	not/a/package	0.001s
     foo_test.go:13: assertion failed
+--- FAIL: TestFoo (0.00s)
 FAIL
 FAIL	github.com/palantir/project/pkgone	0.021s
 ok  	github.com/palantir/project/pkgtwo	0.004s
@@ -43,12 +64,12 @@ FAIL
 			splitAtIdx: len(output),
 		},
 		{
-			name:       "write boundary inside the FAIL summary line",
-			splitAtIdx: bytes.Index([]byte(output), []byte("FAIL\tgithub.com/palantir/project/pkgone")) + 20,
+			name:       "write boundary inside the fail event",
+			splitAtIdx: bytes.Index([]byte(output), []byte(`{"Action":"fail","Package":"github.com/palantir/project/pkgone","Elapsed"`)) + 20,
 		},
 		{
-			name:       "write boundary at the start of the FAIL summary line",
-			splitAtIdx: bytes.Index([]byte(output), []byte("FAIL\tgithub.com/palantir/project/pkgone")),
+			name:       "write boundary at the start of the fail event",
+			splitAtIdx: bytes.Index([]byte(output), []byte(`{"Action":"fail","Package":"github.com/palantir/project/pkgone","Elapsed"`)),
 		},
 		{
 			name:       "write boundary after every byte",
@@ -57,11 +78,10 @@ FAIL
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			var console bytes.Buffer
-			w := &multiWriter{
-				consoleWriter:     &console,
-				rawOutputWriter:   io.Discard,
-				failedPkgs:        []string{},
-				longestPkgNameLen: len("github.com/palantir/project/pkgone"),
+			results := newTestResults()
+			w := &eventWriter{
+				results: results,
+				console: newConsolePrinter(&console, false, len("github.com/palantir/project/pkgone"), colors{}),
 			}
 
 			for remaining := []byte(output); len(remaining) > 0; {
@@ -73,9 +93,8 @@ FAIL
 			}
 			require.NoError(t, w.Flush())
 
-			assert.Equal(t, []string{"github.com/palantir/project/pkgone"}, w.failedPkgs)
-			// all of the content written to the writer must be written to the console exactly once
-			assert.Equal(t, output, console.String())
+			assert.Equal(t, []string{"github.com/palantir/project/pkgone"}, results.failedPkgs())
+			assert.Equal(t, wantConsole, console.String())
 		})
 	}
 }
@@ -0,0 +1,101 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"bytes"
+	goerrors "errors"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// listedTestRegexp matches the lines of "go test -list" output that are the names of tests.
+var listedTestRegexp = regexp.MustCompile(`^(Test|Example|Fuzz)[\p{L}\p{N}_]*$`)
+
+// listPkgTests returns the names of the top-level tests, examples and fuzz tests of the provided packages keyed by
+// package. Packages whose tests cannot be listed (for example, because they fail to build) are not included: the
+// failure is reported when the package is tested.
+func listPkgTests(projectDir string, pkgs []string) (map[string][]string, error) {
+	modPath, err := modulePath(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command("go", append([]string{"test", "-json", "-vet=off", "-list", "^(Test|Example|Fuzz)"}, pkgs...)...)
+	cmd.Dir = projectDir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if _, ok := goerrors.AsType[*exec.ExitError](err); !ok {
+			return nil, errors.Wrapf(err, "%v failed: %s", cmd.Args, stderr.String())
+		}
+	}
+
+	results := newTestResults()
+	for line := range strings.Lines(stdout.String()) {
+		if ev, ok := parseTestEvent(line); ok {
+			results.process(ev)
+		}
+	}
+	pkgTests := make(map[string][]string)
+	for _, pkg := range results.pkgs {
+		relPath, ok := relPkgPath(modPath, pkg.Name)
+		if !ok || pkg.Status != actionPass {
+			continue
+		}
+		for _, line := range pkg.Output {
+			if listedTestRegexp.MatchString(line) {
+				pkgTests[relPath] = append(pkgTests[relPath], line)
+			}
+		}
+	}
+	return pkgTests, nil
+}
+
+// splitPartition returns the packages and tests that belong to the partition when the tests of the packages that have
+// more than "threshold" tests are split across partitions. The tests of a split package are sorted and assigned to
+// partitions in round-robin order so that every partition runs a similar share of them. The remaining packages are
+// assigned to partitions using Apply.
+func (p *Partition) splitPartition(pkgs []string, pkgTests map[string][]string, threshold int) PlanPartition {
+	var wholePkgs []string
+	splitPkgs := make(map[string][]string)
+	for _, pkg := range pkgs {
+		tests := pkgTests[pkg]
+		if len(tests) <= threshold {
+			wholePkgs = append(wholePkgs, pkg)
+			continue
+		}
+		for i, test := range slices.Sorted(slices.Values(tests)) {
+			if i%p.Total == p.Index {
+				splitPkgs[pkg] = append(splitPkgs[pkg], test)
+			}
+		}
+	}
+	partition := PlanPartition{
+		Index:    p.Index,
+		Packages: p.Apply(wholePkgs),
+	}
+	if partition.Packages == nil {
+		partition.Packages = []string{}
+	}
+	if len(splitPkgs) > 0 {
+		partition.SplitPackages = splitPkgs
+	}
+	return partition
+}
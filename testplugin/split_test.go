@@ -0,0 +1,104 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jstemmer/go-junit-report/v2/junit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionSplitPartition(t *testing.T) {
+	pkgs := []string{"./a", "./big", "./c"}
+	pkgTests := map[string][]string{
+		"./a":   {"TestA"},
+		"./big": {"TestE", "TestD", "TestC", "TestB", "TestA"},
+	}
+	for i, want := range []PlanPartition{
+		{Index: 0, Packages: []string{"./a"}, SplitPackages: map[string][]string{"./big": {"TestA", "TestC", "TestE"}}},
+		{Index: 1, Packages: []string{"./c"}, SplitPackages: map[string][]string{"./big": {"TestB", "TestD"}}},
+	} {
+		p := Partition{Index: i, Total: 2}
+		assert.Equal(t, want, p.splitPartition(pkgs, pkgTests, 3))
+	}
+
+	// packages are not split if they do not exceed the threshold
+	p := Partition{Index: 1, Total: 2}
+	assert.Equal(t, PlanPartition{Index: 1, Packages: []string{"./c"}}, p.splitPartition(pkgs, pkgTests, 5))
+}
+
+func TestRunTestCmdSplitsLargePackages(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module testmod\n\ngo 1.21\n"), 0644))
+	for pkg, content := range map[string]string{
+		"big":   "package big\n\nimport \"testing\"\n\nfunc TestA(t *testing.T) {}\nfunc TestB(t *testing.T) {}\nfunc TestC(t *testing.T) { t.Run(\"sub\", func(t *testing.T) {}) }\n",
+		"small": "package small\n\nimport \"testing\"\n\nfunc TestA(t *testing.T) {}\n",
+	} {
+		pkgDir := filepath.Join(tmpDir, pkg)
+		require.NoError(t, os.MkdirAll(pkgDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(pkgDir, pkg+"_test.go"), []byte(content), 0644))
+	}
+
+	param := TestParam{SplitPackageThreshold: 2}
+	pkgTests, err := listPkgTests(tmpDir, []string{"./big", "./small"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"./big": {"TestA", "TestB", "TestC"}, "./small": {"TestA"}}, pkgTests)
+
+	bigTests := make(map[string]bool)
+	var junitOutputs []string
+	for i := range 2 {
+		junitOutput := filepath.Join(tmpDir, fmt.Sprintf("junit-%d.xml", i))
+		junitOutputs = append(junitOutputs, junitOutput)
+		err := RunTestCmd(tmpDir, nil, nil, junitOutput, &Partition{Index: i, Total: 2}, param, &bytes.Buffer{})
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(junitOutput)
+		require.NoError(t, err)
+		var suites junit.Testsuites
+		require.NoError(t, xml.Unmarshal(content, &suites))
+		for _, suite := range suites.Suites {
+			if suite.Name != "testmod/big" {
+				continue
+			}
+			for _, testcase := range suite.Testcases {
+				assert.False(t, bigTests[testcase.Name], "test %s run by multiple partitions", testcase.Name)
+				bigTests[testcase.Name] = true
+			}
+		}
+	}
+	assert.Equal(t, map[string]bool{"TestA": true, "TestB": true, "TestC": true, "TestC/sub": true}, bigTests)
+
+	// the testsuites of the split package in the reports of the partitions are merged into a single testsuite
+	mergedOutput := filepath.Join(tmpDir, "junit.xml")
+	require.NoError(t, MergeJUnitReports(mergedOutput, junitOutputs, &bytes.Buffer{}))
+	content, err := os.ReadFile(mergedOutput)
+	require.NoError(t, err)
+	var merged junit.Testsuites
+	require.NoError(t, xml.Unmarshal(content, &merged))
+	suiteTests := make(map[string]int)
+	for _, suite := range merged.Suites {
+		suiteTests[suite.Name] = suite.Tests
+		assert.Len(t, suite.Testcases, suite.Tests)
+	}
+	assert.Equal(t, map[string]int{"testmod/big": 4, "testmod/small": 1}, suiteTests)
+	assert.Equal(t, 5, merged.Tests)
+}
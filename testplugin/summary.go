@@ -0,0 +1,218 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// pkgSummary contains the number of packages with every outcome. Every package is counted in exactly one category.
+type pkgSummary struct {
+	passed, cached, failed, skipped, noTestFiles, interrupted int
+}
+
+// testSummary contains the number of tests (including subtests) with every outcome.
+type testSummary struct {
+	passed, failed, skipped, flaky, incomplete int
+}
+
+// printSummary prints the summary of the provided results: the number of packages and tests with every outcome, the
+// wall time of the run and the slowest packages and top-level tests. The summary begins with a single line that
+// contains the outcome of the run, which is a failure if "failed" is true. If colors are enabled, the outcome and the
+// counts of failures are colored.
+func printSummary(stdout io.Writer, results *testResults, failed bool, wallTime time.Duration, param SummaryParam, colors colors) {
+	failedPkgs := make(map[string]struct{})
+	for _, pkgName := range results.failedPkgs() {
+		failedPkgs[pkgName] = struct{}{}
+	}
+	var pkgs pkgSummary
+	var tests testSummary
+	for _, pkg := range results.pkgs {
+		_, pkgFailed := failedPkgs[pkg.Name]
+		switch {
+		case pkgFailed:
+			pkgs.failed++
+		case pkg.Status == "":
+			pkgs.interrupted++
+		case pkg.noTestFiles():
+			pkgs.noTestFiles++
+		case pkg.Status == actionSkip:
+			pkgs.skipped++
+		case pkg.cached():
+			pkgs.cached++
+		default:
+			pkgs.passed++
+		}
+		for _, test := range pkg.Tests {
+			switch {
+			case test.isFlaky():
+				tests.flaky++
+			case test.Status == actionPass:
+				tests.passed++
+			case test.Status == actionFail:
+				tests.failed++
+			case test.Status == actionSkip:
+				tests.skipped++
+			default:
+				tests.incomplete++
+			}
+		}
+	}
+
+	outcome := "PASS"
+	if failed {
+		outcome = "FAIL"
+	}
+	outputParts := []string{
+		fmt.Sprintf("Summary: %s (%d package(s) in %v)", colors.paint(resultColors[outcome], outcome), len(results.pkgs), wallTime.Round(10*time.Millisecond)),
+		"packages: " + joinCounts([]summaryCount{
+			{pkgs.passed, "passed", true, ""},
+			{pkgs.failed, "failed", true, ansiRed},
+			{pkgs.skipped, "skipped", true, ""},
+			{pkgs.cached, "cached", true, ""},
+			{pkgs.noTestFiles, "with no test files", true, ""},
+			{pkgs.interrupted, "did not complete", false, ansiYellow},
+		}, colors),
+		"tests:    " + joinCounts([]summaryCount{
+			{tests.passed, "passed", true, ""},
+			{tests.failed, "failed", true, ansiRed},
+			{tests.skipped, "skipped", true, ""},
+			{tests.flaky, "flaky", false, ansiYellow},
+			{tests.incomplete, "did not complete", false, ansiYellow},
+		}, colors),
+	}
+
+	if crashLines := summaryCrashes(results); len(crashLines) > 0 {
+		outputParts = append(outputParts, colors.paint(ansiRed, "panics and data races:"))
+		outputParts = append(outputParts, crashLines...)
+	}
+	if param.Slowest > 0 {
+		if slowest := slowestPkgs(results, param.Slowest); len(slowest) > 0 {
+			outputParts = append(outputParts, "slowest packages:")
+			outputParts = append(outputParts, slowest...)
+		}
+		if slowest := slowestTests(results, param.Slowest); len(slowest) > 0 {
+			outputParts = append(outputParts, "slowest tests:")
+			outputParts = append(outputParts, slowest...)
+		}
+	}
+	_, _ = fmt.Fprintln(stdout, strings.Join(outputParts, "\n\t"))
+}
+
+// summaryCount is a count in a line of the summary. Counts that are not always shown are omitted if they are 0. If
+// color is not empty, counts greater than 0 are highlighted using it.
+type summaryCount struct {
+	count      int
+	label      string
+	alwaysShow bool
+	color      string
+}
+
+func joinCounts(counts []summaryCount, colors colors) string {
+	var parts []string
+	for _, c := range counts {
+		if c.count > 0 || c.alwaysShow {
+			part := fmt.Sprintf("%d %s", c.count, c.label)
+			if c.count > 0 && c.color != "" {
+				part = colors.paint(c.color, part)
+			}
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// summaryCrashes returns the lines of the summary for the tests and packages that panicked or had data races.
+func summaryCrashes(results *testResults) []string {
+	var lines []string
+	for _, pkg := range results.pkgs {
+		crashes := pkg.crashes()
+		names := []string{""}
+		for _, test := range pkg.Tests {
+			names = append(names, test.Name)
+		}
+		for _, name := range names {
+			testCrashes := crashes[name]
+			if len(testCrashes) == 0 {
+				continue
+			}
+			c := primaryCrash(testCrashes)
+			description := c.Message()
+			if c.Kind == crashRace && len(testCrashes) > 1 {
+				description = fmt.Sprintf("%s (%d reports)", description, len(testCrashes))
+			}
+			if name != "" {
+				name = "." + name
+			}
+			lines = append(lines, fmt.Sprintf("\t%s%s: %s", pkg.Name, name, description))
+		}
+	}
+	return lines
+}
+
+// slowestPkgs returns the lines of the summary for the "n" packages that took the longest to test. Packages that did
+// not take any time (for example, because their results were cached) are omitted.
+func slowestPkgs(results *testResults, n int) []string {
+	var pkgs []*pkgResult
+	for _, pkg := range results.pkgs {
+		if pkg.Elapsed > 0 {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	slices.SortStableFunc(pkgs, func(a, b *pkgResult) int {
+		return cmp.Compare(b.Elapsed, a.Elapsed)
+	})
+	var lines []string
+	for _, pkg := range pkgs[:min(n, len(pkgs))] {
+		lines = append(lines, fmt.Sprintf("\t%s\t%s", formatSummaryDuration(pkg.Elapsed), pkg.Name))
+	}
+	return lines
+}
+
+// slowestTests returns the lines of the summary for the "n" top-level tests that took the longest to run. Tests that
+// did not take any measurable time are omitted.
+func slowestTests(results *testResults, n int) []string {
+	type pkgTest struct {
+		pkg  string
+		test *testResult
+	}
+	var tests []pkgTest
+	for _, pkg := range results.pkgs {
+		for _, test := range pkg.Tests {
+			if test.isTopLevel() && test.Elapsed > 0 {
+				tests = append(tests, pkgTest{pkg: pkg.Name, test: test})
+			}
+		}
+	}
+	slices.SortStableFunc(tests, func(a, b pkgTest) int {
+		return cmp.Compare(b.test.Elapsed, a.test.Elapsed)
+	})
+	var lines []string
+	for _, t := range tests[:min(n, len(tests))] {
+		lines = append(lines, fmt.Sprintf("\t%s\t%s.%s", formatSummaryDuration(t.test.Elapsed), t.pkg, t.test.Name))
+	}
+	return lines
+}
+
+// formatSummaryDuration formats the provided duration in seconds with the same precision as the package summary lines
+// of "go test", padded so that the durations in a list are aligned.
+func formatSummaryDuration(d time.Duration) string {
+	return fmt.Sprintf("%7.3fs", d.Seconds())
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintSummary(t *testing.T) {
+	const output = `{"Action":"start","Package":"testmod/fail"}
+{"Action":"run","Package":"testmod/fail","Test":"TestFail"}
+{"Action":"run","Package":"testmod/fail","Test":"TestFail/sub"}
+{"Action":"fail","Package":"testmod/fail","Test":"TestFail/sub","Elapsed":0.5}
+{"Action":"fail","Package":"testmod/fail","Test":"TestFail","Elapsed":0.5}
+{"Action":"run","Package":"testmod/fail","Test":"TestSkip"}
+{"Action":"skip","Package":"testmod/fail","Test":"TestSkip","Elapsed":0}
+{"Action":"output","Package":"testmod/fail","Output":"FAIL\ttestmod/fail\t0.6s\n"}
+{"Action":"fail","Package":"testmod/fail","Elapsed":0.6}
+{"Action":"start","Package":"testmod/slow"}
+{"Action":"run","Package":"testmod/slow","Test":"TestSlow"}
+{"Action":"pass","Package":"testmod/slow","Test":"TestSlow","Elapsed":2}
+{"Action":"run","Package":"testmod/slow","Test":"TestFast"}
+{"Action":"pass","Package":"testmod/slow","Test":"TestFast","Elapsed":0.1}
+{"Action":"output","Package":"testmod/slow","Output":"ok  \ttestmod/slow\t2.2s\n"}
+{"Action":"pass","Package":"testmod/slow","Elapsed":2.2}
+{"Action":"start","Package":"testmod/cached"}
+{"Action":"run","Package":"testmod/cached","Test":"TestPass"}
+{"Action":"pass","Package":"testmod/cached","Test":"TestPass","Elapsed":0}
+{"Action":"output","Package":"testmod/cached","Output":"ok  \ttestmod/cached\t(cached)\n"}
+{"Action":"pass","Package":"testmod/cached","Elapsed":0}
+{"Action":"start","Package":"testmod/notests"}
+{"Action":"output","Package":"testmod/notests","Output":"?   \ttestmod/notests\t[no test files]\n"}
+{"Action":"skip","Package":"testmod/notests","Elapsed":0}
+`
+	results := newTestResults()
+	for line := range strings.Lines(output) {
+		ev, ok := parseTestEvent(line)
+		assert.True(t, ok, line)
+		results.process(ev)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		slowest int
+		want    string
+	}{
+		{
+			name:    "slowest packages and tests",
+			slowest: 2,
+			want: `Summary: FAIL (4 package(s) in 3.5s)
+	packages: 1 passed, 1 failed, 0 skipped, 1 cached, 1 with no test files
+	tests:    3 passed, 2 failed, 1 skipped
+	slowest packages:
+		  2.200s	testmod/slow
+		  0.600s	testmod/fail
+	slowest tests:
+		  2.000s	testmod/slow.TestSlow
+		  0.500s	testmod/fail.TestFail
+`,
+		},
+		{
+			name:    "no slowest packages and tests",
+			slowest: 0,
+			want: `Summary: FAIL (4 package(s) in 3.5s)
+	packages: 1 passed, 1 failed, 0 skipped, 1 cached, 1 with no test files
+	tests:    3 passed, 2 failed, 1 skipped
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			printSummary(&stdout, results, true, 3500*time.Millisecond, SummaryParam{Slowest: tc.slowest}, colors{})
+			assert.Equal(t, tc.want, stdout.String())
+		})
+	}
+}
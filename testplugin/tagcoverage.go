@@ -0,0 +1,366 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/cover"
+)
+
+// untaggedCoverageGroup is the coverage group of the tests of the packages that are not part of any tag. The coverage
+// group of the tests of the packages that are part of a tag is the name of the tag.
+const untaggedCoverageGroup = ""
+
+// tagCoverageProfileFile returns the name of the file in the coverage output directory to which the coverage profile of
+// the tests of the provided coverage group is written.
+func tagCoverageProfileFile(group string) string {
+	if group == untaggedCoverageGroup {
+		return "coverage-untagged.out"
+	}
+	return "coverage-tag-" + group + ".out"
+}
+
+// pkgTags returns the tags of the provided param that every package in the module in the provided project directory is
+// part of, in ascending order, keyed by the path of the package relative to the module root (in the format returned by
+// PkgsToTest). Packages that are not part of any tag are not included.
+func pkgTags(projectDir string, param TestParam) (map[string][]string, error) {
+	tags := make(map[string][]string)
+	for _, tag := range slices.Sorted(maps.Keys(param.Tags)) {
+		pkgs, err := PkgsForTags(projectDir, []string{tag}, param)
+		if err != nil {
+			return nil, err
+		}
+		for _, pkg := range pkgs {
+			tags[pkg] = append(tags[pkg], tag)
+		}
+	}
+	return tags, nil
+}
+
+// groupPkgsByTags groups the provided packages (in the format returned by PkgsToTest) by the tags that they are part of
+// (see pkgTags). The groups are ordered by their first package and the packages of every group retain their order.
+func groupPkgsByTags(pkgs []string, tags map[string][]string) [][]string {
+	var groups [][]string
+	groupIdx := make(map[string]int)
+	for _, pkg := range pkgs {
+		key := strings.Join(tags[pkg], ",")
+		idx, ok := groupIdx[key]
+		if !ok {
+			idx = len(groups)
+			groupIdx[key] = idx
+			groups = append(groups, nil)
+		}
+		groups[idx] = append(groups[idx], pkg)
+	}
+	return groups
+}
+
+// profileGroups returns the coverage groups to which the profile of a command that tested the packages with the
+// provided import paths is attributed: the tags of the packages (see pkgTags) or untaggedCoverageGroup if they are not
+// part of any tag. A profile is not attributed to any group if the packages are part of different tags (for example,
+// the profile of a command that retried failed tests in multiple packages) or if the command did not test any packages.
+func profileGroups(modPath string, pkgs []string, tags map[string][]string) []string {
+	var groups []string
+	for i, pkg := range pkgs {
+		relPath, ok := relPkgPath(modPath, pkg)
+		if !ok {
+			return nil
+		}
+		if i > 0 && !slices.Equal(tags[relPath], groups) {
+			return nil
+		}
+		groups = tags[relPath]
+	}
+	if len(pkgs) == 0 {
+		return nil
+	}
+	if len(groups) == 0 {
+		return []string{untaggedCoverageGroup}
+	}
+	return groups
+}
+
+// tagCoverageProfiles merges the provided coverage profiles of every coverage group and returns the merged profiles
+// keyed by group. The profile of every group includes all of the blocks of the provided merged profiles of all of the
+// tests (a test binary only reports the coverage of the packages that it links), so that the coverage of all of the
+// groups is relative to the same statements.
+func tagCoverageProfiles(merged []*cover.Profile, groupFiles map[string][]string) (map[string][]*cover.Profile, error) {
+	uncovered := uncoveredProfiles(merged)
+	groupProfiles := make(map[string][]*cover.Profile)
+	for group, files := range groupFiles {
+		profiles, err := readCoverageProfiles(files)
+		if err != nil {
+			return nil, err
+		}
+		if groupProfiles[group], err = unionCoverageProfiles(uncovered, profiles); err != nil {
+			return nil, err
+		}
+	}
+	return groupProfiles, nil
+}
+
+// writeTagCoverage writes the provided merged profile of every coverage group (see tagCoverageProfiles) to the
+// provided output directory (see tagCoverageProfileFile). Prints the coverage of the tests of every group, the coverage
+// of the union of the tests of all of the tags and the functions that are only covered by the tests of a single tag.
+func writeTagCoverage(projectDir, outputDir string, merged []*cover.Profile, groupProfiles map[string][]*cover.Profile, stdout io.Writer) error {
+	groups := slices.Sorted(maps.Keys(groupProfiles))
+	if len(groups) > 0 && groups[0] == untaggedCoverageGroup {
+		// the packages that are not part of any tag are listed after the tags
+		groups = append(groups[1:], untaggedCoverageGroup)
+	}
+	for _, group := range groups {
+		var content strings.Builder
+		writeCoverageProfile(&content, groupProfiles[group])
+		if err := os.WriteFile(filepath.Join(outputDir, tagCoverageProfileFile(group)), []byte(content.String()), 0644); err != nil {
+			return errors.Wrapf(err, "failed to write coverage profile")
+		}
+	}
+	combined, err := combinedTagCoverage(merged, groupProfiles)
+	if err != nil {
+		return err
+	}
+
+	type coverageLine struct {
+		label          string
+		covered, total int
+	}
+	var lines []coverageLine
+	for _, group := range groups {
+		label := group
+		if group == untaggedCoverageGroup {
+			label = "untagged packages"
+		}
+		covered, total := coveredStatements(groupProfiles[group])
+		lines = append(lines, coverageLine{label: label, covered: covered, total: total})
+	}
+	if len(groups) > 0 && groups[0] != untaggedCoverageGroup {
+		// the union of the coverage of the tests of all of the tags
+		covered, total := coveredStatements(combined)
+		lines = append(lines, coverageLine{label: "combined tags", covered: covered, total: total})
+	}
+	labelLen := 0
+	for _, line := range lines {
+		labelLen = max(labelLen, len(line.label))
+	}
+	outputParts := []string{"Coverage by tag:"}
+	for _, line := range lines {
+		outputParts = append(outputParts, fmt.Sprintf("%-*s %s of statements (%d/%d)", labelLen+1, line.label+":", formatCoveragePercent(line.covered, line.total), line.covered, line.total))
+	}
+	_, _ = fmt.Fprintln(stdout, strings.Join(outputParts, "\n\t"))
+
+	printTagOnlyFuncs(projectDir, groups, groupProfiles, stdout)
+	return nil
+}
+
+// combinedTagCoverage returns the union of the provided merged profiles of the coverage groups of all of the tags
+// (see tagCoverageProfiles), which includes all of the blocks of the provided merged profiles of all of the tests.
+func combinedTagCoverage(merged []*cover.Profile, groupProfiles map[string][]*cover.Profile) ([]*cover.Profile, error) {
+	combined := uncoveredProfiles(merged)
+	for _, group := range slices.Sorted(maps.Keys(groupProfiles)) {
+		if group == untaggedCoverageGroup {
+			continue
+		}
+		var err error
+		if combined, err = unionCoverageProfiles(combined, groupProfiles[group]); err != nil {
+			return nil, err
+		}
+	}
+	return combined, nil
+}
+
+// printTagOnlyFuncs prints the functions that are covered by the tests of a tag, but not by the tests of any other
+// coverage group, for every tag. Nothing is printed for a tag if no other coverage groups were tested.
+func printTagOnlyFuncs(projectDir string, groups []string, groupProfiles map[string][]*cover.Profile, stdout io.Writer) {
+	if len(groups) < 2 {
+		return
+	}
+	modPath, _ := modulePath(projectDir)
+	funcs := newFuncExtents(projectDir, modPath)
+	coveredByGroup := make(map[string]map[coverageFunc]struct{})
+	for _, group := range groups {
+		coveredByGroup[group] = funcs.coveredFuncs(groupProfiles[group])
+	}
+	for _, group := range groups {
+		if group == untaggedCoverageGroup {
+			continue
+		}
+		var only []coverageFunc
+		for fn := range coveredByGroup[group] {
+			coveredByOther := false
+			for _, other := range groups {
+				if _, ok := coveredByGroup[other][fn]; ok && other != group {
+					coveredByOther = true
+					break
+				}
+			}
+			if !coveredByOther {
+				only = append(only, fn)
+			}
+		}
+		if len(only) == 0 {
+			continue
+		}
+		slices.SortFunc(only, func(a, b coverageFunc) int {
+			return cmp.Or(cmp.Compare(a.file, b.file), cmp.Compare(a.line, b.line))
+		})
+		outputParts := []string{fmt.Sprintf("%d function(s) covered only by the tests of tag %s:", len(only), group)}
+		for _, fn := range only {
+			outputParts = append(outputParts, fn.String())
+		}
+		_, _ = fmt.Fprintln(stdout, strings.Join(outputParts, "\n\t"))
+	}
+}
+
+// coverageFunc is a function in a file of a coverage profile.
+type coverageFunc struct {
+	// file is the path of the file relative to the project directory.
+	file string
+	line int
+	// name is the name of the function, qualified by its receiver type for methods (for example, "(*Foo).Bar").
+	name string
+}
+
+func (f coverageFunc) String() string {
+	return fmt.Sprintf("%s:%d: %s", f.file, f.line, f.name)
+}
+
+// funcExtent is a function and the positions of its start and end.
+type funcExtent struct {
+	coverageFunc
+	startLine, startCol, endLine, endCol int
+}
+
+// contains returns true if the provided block of a coverage profile is in the function.
+func (f funcExtent) contains(b cover.ProfileBlock) bool {
+	afterStart := b.StartLine > f.startLine || b.StartLine == f.startLine && b.StartCol >= f.startCol
+	beforeEnd := b.EndLine < f.endLine || b.EndLine == f.endLine && b.EndCol <= f.endCol
+	return afterStart && beforeEnd
+}
+
+// funcExtents parses the functions of the files of coverage profiles of packages in the module in a project directory.
+// The functions of every file are only parsed once.
+type funcExtents struct {
+	projectDir, modPath string
+	files               map[string][]funcExtent
+}
+
+func newFuncExtents(projectDir, modPath string) *funcExtents {
+	return &funcExtents{
+		projectDir: projectDir,
+		modPath:    modPath,
+		files:      make(map[string][]funcExtent),
+	}
+}
+
+// coveredFuncs returns the functions that contain any covered block of the provided profiles. The profiles of files
+// that are not in the module are ignored.
+func (e *funcExtents) coveredFuncs(profiles []*cover.Profile) map[coverageFunc]struct{} {
+	covered := make(map[coverageFunc]struct{})
+	for _, profile := range profiles {
+		relPath, ok := strings.CutPrefix(profile.FileName, e.modPath+"/")
+		if !ok || e.modPath == "" {
+			continue
+		}
+		funcs := e.fileFuncs(relPath)
+		for _, b := range profile.Blocks {
+			if b.Count == 0 {
+				continue
+			}
+			for _, fn := range funcs {
+				if fn.contains(b) {
+					covered[fn.coverageFunc] = struct{}{}
+					break
+				}
+			}
+		}
+	}
+	return covered
+}
+
+// fileFuncs returns the functions of the file with the provided path relative to the project directory. A file that
+// cannot be parsed (for example, because it was modified after the tests were run) has no functions.
+func (e *funcExtents) fileFuncs(relPath string) []funcExtent {
+	if funcs, ok := e.files[relPath]; ok {
+		return funcs
+	}
+	var funcs []funcExtent
+	e.files[relPath] = funcs
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filepath.Join(e.projectDir, filepath.FromSlash(relPath)), nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		start, end := fset.Position(fn.Pos()), fset.Position(fn.End())
+		funcs = append(funcs, funcExtent{
+			coverageFunc: coverageFunc{
+				file: relPath,
+				line: start.Line,
+				name: funcName(fn),
+			},
+			startLine: start.Line,
+			startCol:  start.Column,
+			endLine:   end.Line,
+			endCol:    end.Column,
+		})
+	}
+	e.files[relPath] = funcs
+	return funcs
+}
+
+// funcName returns the name of the provided function qualified by its receiver type if it is a method: for example,
+// "Foo.Bar" or "(*Foo).Bar".
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+	recvType := fn.Recv.List[0].Type
+	pointer := false
+	if star, ok := recvType.(*ast.StarExpr); ok {
+		pointer = true
+		recvType = star.X
+	}
+	// the type parameters of generic receivers are omitted
+	switch typ := recvType.(type) {
+	case *ast.IndexExpr:
+		recvType = typ.X
+	case *ast.IndexListExpr:
+		recvType = typ.X
+	}
+	recvName := "?"
+	if ident, ok := recvType.(*ast.Ident); ok {
+		recvName = ident.Name
+	}
+	if pointer {
+		return "(*" + recvName + ")." + fn.Name.Name
+	}
+	return recvName + "." + fn.Name.Name
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/palantir/pkg/matcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTestCmdTagCoverage(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module testmod\n\ngo 1.21\n"), 0644))
+	for file, content := range map[string]string{
+		"a/a.go":              "package a\n\ntype T struct{}\n\nfunc Add(x, y int) int {\n\treturn x + y\n}\n\nfunc (*T) Sub(x, y int) int {\n\treturn x - y\n}\n",
+		"a/a_test.go":         "package a\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {\n\tAdd(1, 2)\n}\n",
+		"it/it_test.go":       "package it\n\nimport (\n\t\"testing\"\n\n\t\"testmod/a\"\n)\n\nfunc TestIt(t *testing.T) {\n\ta.Add(1, 2)\n\tnew(a.T).Sub(1, 2)\n}\n",
+		"other/other.go":      "package other\n\nfunc Other() int {\n\treturn 1\n}\n",
+		"other/other_test.go": "package other\n\nimport \"testing\"\n\nfunc TestOther(t *testing.T) {}\n",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(tmpDir, file)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, file), []byte(content), 0644))
+	}
+
+	var stdout bytes.Buffer
+	coverageDir := filepath.Join(tmpDir, "coverage")
+	param := TestParam{
+		Tags: map[string]matcher.Matcher{
+			"unit":        matcher.Path("a"),
+			"integration": matcher.Path("it"),
+		},
+		CoverageOutput: coverageDir,
+	}
+	err := RunTestCmd(tmpDir, nil, nil, "", nil, param, &stdout)
+	require.NoError(t, err, stdout.String())
+	assert.Contains(t, stdout.String(), "Coverage by tag:\n"+
+		"\tintegration:       66.7% of statements (2/3)\n"+
+		"\tunit:              33.3% of statements (1/3)\n"+
+		"\tuntagged packages: 0.0% of statements (0/3)\n"+
+		"\tcombined tags:     66.7% of statements (2/3)\n"+
+		"1 function(s) covered only by the tests of tag integration:\n"+
+		"\ta/a.go:9: (*T).Sub\n")
+	for _, file := range []string{"coverage-tag-integration.out", "coverage-tag-unit.out", "coverage-untagged.out"} {
+		assert.FileExists(t, filepath.Join(coverageDir, file))
+	}
+}
+
+func TestGroupPkgsByTags(t *testing.T) {
+	tags := map[string][]string{
+		"./a":  {"unit"},
+		"./b":  {"integration", "unit"},
+		"./c":  {"unit"},
+		"./it": {"integration"},
+	}
+	assert.Equal(t, [][]string{
+		{"./a", "./c"},
+		{"./b"},
+		{"./d", "./e"},
+		{"./it"},
+	}, groupPkgsByTags([]string{"./a", "./b", "./c", "./d", "./it", "./e"}, tags))
+}
+
+func TestProfileGroups(t *testing.T) {
+	tags := map[string][]string{
+		"./a":  {"unit"},
+		"./b":  {"integration", "unit"},
+		"./c":  {"unit"},
+		"./it": {"integration"},
+	}
+	for _, tc := range []struct {
+		name string
+		pkgs []string
+		want []string
+	}{
+		{name: "single tag", pkgs: []string{"testmod/a", "testmod/c"}, want: []string{"unit"}},
+		{name: "multiple tags", pkgs: []string{"testmod/b"}, want: []string{"integration", "unit"}},
+		{name: "untagged", pkgs: []string{"testmod", "testmod/d"}, want: []string{untaggedCoverageGroup}},
+		{name: "different tags", pkgs: []string{"testmod/a", "testmod/it"}, want: nil},
+		{name: "tagged and untagged", pkgs: []string{"testmod/a", "testmod/d"}, want: nil},
+		{name: "no packages", pkgs: nil, want: nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, profileGroups("testmod", tc.pkgs, tags))
+		})
+	}
+}
+
+func TestFuncName(t *testing.T) {
+	const src = `package foo
+
+func Foo() {}
+
+func (Bar) Value() {}
+
+func (b *Bar) Pointer() {}
+
+func (g *Generic[T]) Generic() {}
+
+func (p Pair[K, V]) Pair() {}
+`
+	file, err := parser.ParseFile(token.NewFileSet(), "foo.go", src, 0)
+	require.NoError(t, err)
+	var got []string
+	for _, decl := range file.Decls {
+		got = append(got, funcName(decl.(*ast.FuncDecl)))
+	}
+	assert.Equal(t, []string{"Foo", "Bar.Value", "(*Bar).Pointer", "(*Generic).Generic", "Pair.Pair"}, got)
+}
@@ -18,82 +18,456 @@ import (
 	goerrors "errors"
 	"fmt"
 	"io"
+	"maps"
+	"os"
 	"os/exec"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/palantir/pkg/matcher"
 	"github.com/palantir/pkg/pkgpath"
 	"github.com/pkg/errors"
+	"golang.org/x/tools/cover"
 )
 
 const GoJUnitReport = "gojunitreport"
 
 func RunTestCmd(projectDir string, testArgs, tags []string, junitOutput string, partition *Partition, param TestParam, stdout io.Writer) (rErr error) {
+	start := time.Now()
 	if err := param.Validate(); err != nil {
 		return err
 	}
-	pkgs, err := PkgsToTest(projectDir, tags, partition, param, stdout)
+	if param.QueueDir != "" && partition != nil {
+		return errors.Errorf("a partition cannot be specified if a queue directory is specified")
+	}
+	if param.QueueDir != "" && param.QueueID == "" {
+		return errors.Errorf("a queue ID must be specified if a queue directory is specified")
+	}
+	selected, err := testsToRun(projectDir, tags, partition, param)
 	if err != nil {
 		return err
 	}
+	pkgs := selected.pkgs()
 	if len(pkgs) == 0 {
-		return errors.Errorf("no packages to test")
+		if partition == nil {
+			return errors.Errorf("no packages to test")
+		}
+		// partitions may legitimately be empty (for example, if there are more partitions than packages): the run
+		// succeeds and any requested output files are written without results
+		_, _ = fmt.Fprintf(stdout, "%v has no packages to test\n", partition)
 	}
 
-	args := []string{
-		"test",
+	// "-json" output always contains the output of all tests, so the "-v" flag is consumed rather than provided to the
+	// command: it determines whether the output of passing tests is printed to the console unless the console output is
+	// specified. The JUnit output always contains the output of all tests regardless of what is printed to the console.
+	testArgs, verbose := removeVerboseFlag(testArgs)
+	if param.ConsoleOutput != "" {
+		verbose = param.ConsoleOutput == ConsoleOutputVerbose
 	}
-	args = append(args, testArgs...)
 
-	if junitOutput != "" {
-		args = append(args, "-v")
+	// if a coverage output directory is specified, every command writes its own coverage profile and the profiles are
+	// merged once all of the packages have been tested. Coverage is also collected if coverage minimums or a coverage
+	// base are configured so that they can be checked, unless this run only tests a subset of the packages of a
+	// partitioned run (in which case they are checked when the coverage profiles of all of the partitions are merged).
+	var coverage *coverageCollector
+	checkCoverageParams := (param.Coverage.hasMins() || param.CoverageBase != "") && partition == nil && param.QueueDir == ""
+	if param.CoverageOutput != "" || checkCoverageParams {
+		if coverage, testArgs, err = newCoverageCollector(projectDir, param.CoverageOutput, param, testArgs); err != nil {
+			return err
+		}
+		defer coverage.cleanup()
 	}
-	args = append(args, pkgs...)
-	cmd := exec.Command("go", args...)
-	cmd.Dir = projectDir
 
 	maxPkgLen, err := longestPkgNameLen(pkgs, projectDir)
 	if err != nil {
 		return err
 	}
 
-	rawOutputWriter := io.Discard
+	// by default, the packages for which all tests are run are tested by a single command. If jobs are specified, every
+	// package is tested by its own command instead. Every split package is tested by its own command that runs only
+	// the tests assigned to this partition. If the coverage of the tests of every tag is collected, the packages are
+	// instead tested by a command for every combination of tags so that the coverage profile of every command can be
+	// attributed to its tags.
+	var cmds []*exec.Cmd
+	if param.Jobs > 0 {
+		for _, pkg := range selected.Packages {
+			cmds = append(cmds, goTestCmd(projectDir, testArgs, []string{pkg}))
+		}
+	} else if coverage.reportsTags() {
+		tags, err := pkgTags(projectDir, param)
+		if err != nil {
+			return err
+		}
+		for _, groupPkgs := range groupPkgsByTags(selected.Packages, tags) {
+			cmds = append(cmds, goTestCmd(projectDir, testArgs, groupPkgs))
+		}
+	} else if len(selected.Packages) > 0 {
+		cmds = append(cmds, goTestCmd(projectDir, testArgs, selected.Packages))
+	}
+	for _, pkg := range slices.Sorted(maps.Keys(selected.SplitPackages)) {
+		cmds = append(cmds, goTestCmd(projectDir, append(slices.Clone(testArgs), "-run", runRegexp(selected.SplitPackages[pkg])), []string{pkg}))
+	}
+	nextCmd := func() (*exec.Cmd, error) {
+		if len(cmds) == 0 {
+			return nil, nil
+		}
+		cmd := cmds[0]
+		cmds = cmds[1:]
+		return cmd, nil
+	}
+
+	// if a queue directory is specified, every package is instead tested by its own command once it is claimed from
+	// the queue shared with the other nodes
+	var queue *testQueue
+	if param.QueueDir != "" {
+		modPath, err := modulePath(projectDir)
+		if err != nil {
+			return err
+		}
+		if queue, err = joinTestQueue(param.QueueDir, param.QueueID, modPath, pkgs); err != nil {
+			return err
+		}
+		if queue.summary != nil {
+			// the queue completed before this node joined it, so all of its packages were tested by the other nodes
+			printQueueSummary(stdout, *queue.summary)
+			return queueFailuresError(param.QueueDir, *queue.summary, nil)
+		}
+		queue.startHeartbeat()
+		defer queue.stopHeartbeat()
+		nextCmd = func() (*exec.Cmd, error) {
+			pkg, ok, err := queue.claim()
+			if err != nil || !ok {
+				return nil, err
+			}
+			return goTestCmd(projectDir, testArgs, []string{pkg}), nil
+		}
+	}
 
+	// colors are detected based on the console before it is wrapped by the progress reporter
+	colors := colors{enabled: useColor(stdout, param.Color)}
+	results := newTestResults()
+	if !param.Summary.Disabled {
+		// the summary is printed after all of the other output, including when the run fails, but before the error
+		// that reports the failure. It is omitted if no packages were tested (for example, for an empty partition).
+		defer func() {
+			if len(results.pkgs) > 0 {
+				printSummary(stdout, results, rErr != nil, time.Since(start), param.Summary, colors)
+			}
+		}()
+	}
+	if param.JSONOutput != "" {
+		// written after all of the other output (including when the run fails) so that it reflects the outcome of the
+		// run
+		defer func() {
+			if err := writeJSONResults(param.JSONOutput, projectDir, tags, partition, pkgs, results, rErr != nil, start); err != nil && rErr == nil {
+				rErr = err
+			}
+		}()
+	}
 	if junitOutput != "" {
-		junitInputPipeWriter, closeJUnitReporter, err := startJUnitReporter(junitOutput)
+		closeJUnitReporter, err := startJUnitReporter(junitOutput, results)
 		if err != nil {
 			return err
 		}
 		defer closeJUnitReporter()
-		rawOutputWriter = junitInputPipeWriter
 	}
 
-	failedPkgs, err := executeTestCmd(cmd, stdout, rawOutputWriter, maxPkgLen)
+	// signals are handled while the tests run so that the results of the tests that completed are still reported (and
+	// written to the JUnit output) if the run is interrupted
+	interrupts := startInterruptHandler()
+	defer interrupts.stop()
+
+	// all of the output written while the packages are tested is written through the progress reporter so that it can
+	// clear the progress line before any output is written
+	var progress *progressReporter
+	if param.Progress {
+		total := len(pkgs)
+		if queue != nil {
+			// the number of packages that this node tests is not known in advance
+			total = 0
+		}
+		interval := param.ProgressInterval
+		if interval == 0 {
+			interval = DefaultProgressInterval
+		}
+		progress = startProgressReporter(stdout, total, interval)
+		// stopped before the summary is printed (deferred functions run in reverse order)
+		defer progress.stop()
+		stdout = progress
+	}
+	opts := execOptions{
+		verbose:           verbose,
+		maxPkgLen:         maxPkgLen,
+		inactivityTimeout: param.InactivityTimeout,
+		interrupts:        interrupts,
+		failFast:          param.FailFast,
+		progress:          progress,
+		colors:            colors,
+		coverage:          coverage,
+	}
+	err = executeTestCmds(func() (*exec.Cmd, error) {
+		if interrupts.stopped() {
+			return nil, nil
+		}
+		return nextCmd()
+	}, param.Jobs, stdout, results, opts)
+	if _, ok := goerrors.AsType[*exec.ExitError](err); err != nil && !ok {
+		return err
+	}
+	if err := writeGoroutineDumps(param.ArtifactsDir, results, stdout); err != nil {
+		return err
+	}
+	if sig := interrupts.interrupted(); sig != nil {
+		return interruptedError(results, sig)
+	}
+	initialFailedPkgs := results.failedPkgs()
+	if len(initialFailedPkgs) > 0 && param.Retries > 0 && !param.FailFast {
+		if err := retryFailedTests(projectDir, testArgs, param.Retries, results, stdout, opts); err != nil {
+			return err
+		}
+	}
+
+	var coverageProfiles []*cover.Profile
+	var tagProfiles map[string][]*cover.Profile
+	if coverage != nil {
+		if coverageProfiles, tagProfiles, err = coverage.finish(stdout); err != nil {
+			return err
+		}
+	}
 
-	if len(failedPkgs) > 0 {
-		numFailedPkgs := len(failedPkgs)
-		outputParts := append([]string{fmt.Sprintf("%d package(s) had failing tests:", numFailedPkgs)}, failedPkgs...)
-		return errors.Errorf("%s", strings.Join(outputParts, "\n\t"))
+	if param.TimingsOutput != "" {
+		if err := writeTimings(param.TimingsOutput, results); err != nil {
+			return err
+		}
+	}
+
+	// the failures of the packages tested by the other nodes are reported after the failures of the packages tested by
+	// this node, which are reported in more detail
+	var queueErr error
+	if queue != nil {
+		queueErr = queue.finish(results, stdout)
+	}
+
+	if flakyTests := results.flakyTests(); len(flakyTests) > 0 {
+		outputParts := append([]string{fmt.Sprintf("%d flaky test(s) passed on retry:", len(flakyTests))}, flakyTests...)
+		_, _ = fmt.Fprintln(stdout, strings.Join(outputParts, "\n\t"))
+	}
+
+	if failedPkgs := results.failedPkgs(); len(failedPkgs) > 0 {
+		if param.FailFast {
+			if queue != nil {
+				// the packages that this node did not test are tested by the other nodes
+				pkgs = nil
+			}
+			return failFastError(projectDir, results, failedPkgs, pkgs, param)
+		}
+		return failedPkgsError(projectDir, results, failedPkgs, param)
 	}
 
 	// the exit status of "go test" is the authoritative signal for whether the tests succeeded: the
-	// packages parsed out of the command's output are only used to provide a more useful error
-	// message. A non-zero exit status with no parsed failures occurs for cases such as packages that
-	// fail to compile (where the output does not contain a parseable "FAIL" summary line).
+	// failed packages recorded from the command's events are only used to provide a more useful error
+	// message. A non-zero exit status with no failed packages occurs for cases such as invalid
+	// arguments (where the go command fails before any package is tested).
 	if err != nil {
-		if exitErr, ok := goerrors.AsType[*exec.ExitError](err); ok {
+		exitErr, ok := goerrors.AsType[*exec.ExitError](err)
+		if !ok {
+			return err
+		}
+		if len(initialFailedPkgs) == 0 {
 			return errors.Wrapf(exitErr, `"go test" failed and no failing packages were detected in its output`)
 		}
-		return err
+		// all of the packages that failed passed when their tests were retried
+	}
+	if queueErr != nil {
+		return queueErr
 	}
 
+	// coverage minimums are only enforced if all of the tests passed
+	if checkCoverageParams && coverageProfiles != nil {
+		return checkCoverage(projectDir, coverageProfiles, tagProfiles, tags, param, stdout)
+	}
 	return nil
 }
 
+// failedPkgsError returns the error that reports the provided failed packages along with the tests in each package that
+// failed and the locations of their first failures (see failureLocator). If retries were enabled, only the tests that
+// failed on every attempt are listed. The packages that failed to build (see buildFailure) are reported separately
+// for every kind of build failure along with the messages of the compiler or "go vet", and the packages that hung are
+// reported separately along with the tests that were running and the files that contain their goroutine dumps.
+func failedPkgsError(projectDir string, results *testResults, failedPkgs []string, param TestParam) error {
+	var hungPkgs, otherPkgs []string
+	buildFailedPkgs := make(map[string][]string)
+	for _, pkgName := range failedPkgs {
+		pkg := results.byName[pkgName]
+		switch {
+		case pkg.Hung:
+			hungPkgs = append(hungPkgs, pkgName)
+		case pkg.buildFailure() != "":
+			buildFailedPkgs[pkg.buildFailure()] = append(buildFailedPkgs[pkg.buildFailure()], pkgName)
+		default:
+			otherPkgs = append(otherPkgs, pkgName)
+		}
+	}
+
+	var sections []string
+	// packages that failed to build because of the same dependency share its build output, which is only listed once
+	listedBuildOutput := make(map[string]struct{})
+	for _, kind := range []string{buildFailureCompile, buildFailureVet, buildFailureSetup} {
+		pkgNames := buildFailedPkgs[kind]
+		if len(pkgNames) == 0 {
+			continue
+		}
+		outputParts := []string{fmt.Sprintf(buildFailureHeaders[kind], len(pkgNames))}
+		for _, pkgName := range pkgNames {
+			pkg := results.byName[pkgName]
+			if dependency := pkg.failedDependency(); kind == buildFailureCompile && dependency != "" {
+				outputParts = append(outputParts, fmt.Sprintf("%s (dependency %s failed to build)", pkgName, dependency))
+			} else {
+				outputParts = append(outputParts, pkgName)
+			}
+			if _, ok := listedBuildOutput[pkg.FailedBuild]; ok && pkg.FailedBuild != "" {
+				continue
+			}
+			listedBuildOutput[pkg.FailedBuild] = struct{}{}
+			for _, line := range pkg.BuildOutput {
+				// the lines that identify the package that is being built are omitted because the package is listed
+				if !strings.HasPrefix(line, "# ") {
+					outputParts = append(outputParts, "\t"+line)
+				}
+			}
+		}
+		sections = append(sections, strings.Join(outputParts, "\n\t"))
+	}
+	if len(otherPkgs) > 0 {
+		header := fmt.Sprintf("%d package(s) had failing tests:", len(otherPkgs))
+		if param.Retries > 0 {
+			header = fmt.Sprintf("%d package(s) had failing tests after %d retries:", len(otherPkgs), param.Retries)
+		}
+		outputParts := []string{header}
+		locator := newFailureLocator(projectDir)
+		for _, pkgName := range otherPkgs {
+			outputParts = append(outputParts, pkgName)
+			for _, failure := range locator.testFailures(results.byName[pkgName]) {
+				outputParts = append(outputParts, "\t"+failure.String(pkgName))
+			}
+		}
+		sections = append(sections, strings.Join(outputParts, "\n\t"))
+	}
+	if len(hungPkgs) > 0 {
+		outputParts := []string{fmt.Sprintf("%d package(s) hung (no output for %v):", len(hungPkgs), param.InactivityTimeout)}
+		for _, pkgName := range hungPkgs {
+			pkg := results.byName[pkgName]
+			outputParts = append(outputParts, pkgName)
+			for _, test := range pkg.hungTests() {
+				outputParts = append(outputParts, "\trunning: "+test)
+			}
+			if pkg.GoroutineDumpFile != "" {
+				outputParts = append(outputParts, "\tgoroutine dump: "+pkg.GoroutineDumpFile)
+			}
+		}
+		sections = append(sections, strings.Join(outputParts, "\n\t"))
+	}
+	return errors.Errorf("%s", strings.Join(sections, "\n"))
+}
+
+// failFastError returns the error that reports the provided failed packages when testing was stopped because of
+// FailFast. The error also lists the packages that did not complete because their tests were stopped and the
+// packages in "pkgs" (in the format returned by PkgsToTest) that were not tested at all.
+func failFastError(projectDir string, results *testResults, failedPkgs, pkgs []string, param TestParam) error {
+	modPath, err := modulePath(projectDir)
+	if err != nil {
+		return err
+	}
+	tested := make(map[string]struct{})
+	for _, pkg := range results.pkgs {
+		if relPath, ok := relPkgPath(modPath, pkg.Name); ok {
+			tested[relPath] = struct{}{}
+		}
+	}
+	var notTestedPkgs []string
+	for _, pkg := range pkgs {
+		if _, ok := tested[pkg]; !ok {
+			notTestedPkgs = append(notTestedPkgs, pkg)
+		}
+	}
+
+	// the failed tests are not retried when failing fast
+	param.Retries = 0
+	sections := []string{failedPkgsError(projectDir, results, failedPkgs, param).Error()}
+	if stoppedPkgs := results.interruptedPkgs(); len(stoppedPkgs) > 0 {
+		sections = append(sections, strings.Join(append([]string{fmt.Sprintf("%d package(s) were stopped before they completed because of --fail-fast:", len(stoppedPkgs))}, stoppedPkgs...), "\n\t"))
+	}
+	if len(notTestedPkgs) > 0 {
+		sections = append(sections, strings.Join(append([]string{fmt.Sprintf("%d package(s) were not tested because of --fail-fast:", len(notTestedPkgs))}, notTestedPkgs...), "\n\t"))
+	}
+	return errors.Errorf("%s", strings.Join(sections, "\n"))
+}
+
+// buildFailureHeaders contains the format of the header of the section of the error returned by failedPkgsError for
+// every kind of build failure.
+var buildFailureHeaders = map[string]string{
+	buildFailureCompile: "%d package(s) failed to build:",
+	buildFailureVet:     "%d package(s) failed vet checks:",
+	buildFailureSetup:   "%d package(s) failed setup:",
+}
+
+// interruptedError returns the error that reports that the run was interrupted by the provided signal along with the
+// packages and tests that had not completed.
+func interruptedError(results *testResults, sig os.Signal) error {
+	outputParts := []string{fmt.Sprintf("tests were interrupted by signal %q", sig)}
+	for _, pkgName := range results.interruptedPkgs() {
+		outputParts = append(outputParts, pkgName)
+		for _, test := range results.byName[pkgName].interruptedTests() {
+			outputParts = append(outputParts, "\tinterrupted: "+test)
+		}
+	}
+	return errors.Errorf("%s", strings.Join(outputParts, "\n\t"))
+}
+
+// goTestCmd returns the "go test -json" command that tests the provided packages using the provided arguments.
+func goTestCmd(projectDir string, testArgs, pkgs []string) *exec.Cmd {
+	args := []string{
+		"test",
+		"-json",
+	}
+	args = append(args, testArgs...)
+	args = append(args, pkgs...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = projectDir
+	return cmd
+}
+
+// removeVerboseFlag returns the provided "go test" arguments with any "-v" flags removed and whether the arguments
+// enabled verbose output.
+func removeVerboseFlag(testArgs []string) ([]string, bool) {
+	var args []string
+	verbose := false
+	for _, arg := range testArgs {
+		switch arg {
+		case "-v", "--v", "-v=true", "--v=true":
+			verbose = true
+		case "-v=false", "--v=false":
+			verbose = false
+		default:
+			args = append(args, arg)
+		}
+	}
+	return args, verbose
+}
+
 // PkgsToTest returns the list of packages to test based on tags, exclusions, and partitioning.
 // Returns an error if partition parsing fails or no packages are found (unless partitioning results in empty set).
+// If the partition has a plan, the packages assigned to the partition by the plan are returned.
 func PkgsToTest(projectDir string, tags []string, partition *Partition, param TestParam, stdout io.Writer) ([]string, error) {
+	if partition != nil && partition.Plan != nil {
+		planPartition, err := partition.Plan.partition(*partition)
+		if err != nil {
+			return nil, err
+		}
+		return planPartition.pkgs(), nil
+	}
 	pkgs, err := PkgsForTags(projectDir, tags, param)
 	if err != nil {
 		return nil, err
@@ -104,6 +478,29 @@ func PkgsToTest(projectDir string, tags []string, partition *Partition, param Te
 	return pkgs, nil
 }
 
+// testsToRun returns the packages and tests that are run based on tags, exclusions, and partitioning. If
+// param.SplitPackageThreshold is set and the tests are partitioned, the tests of the packages that have more tests
+// than the threshold are split across the partitions.
+func testsToRun(projectDir string, tags []string, partition *Partition, param TestParam) (PlanPartition, error) {
+	if partition != nil && partition.Plan != nil {
+		return partition.Plan.partition(*partition)
+	}
+	pkgs, err := PkgsForTags(projectDir, tags, param)
+	if err != nil {
+		return PlanPartition{}, err
+	}
+	if partition == nil {
+		return PlanPartition{Packages: pkgs}, nil
+	}
+	var pkgTests map[string][]string
+	if param.SplitPackageThreshold > 0 && partition.Total > 1 {
+		if pkgTests, err = listPkgTests(projectDir, pkgs); err != nil {
+			return PlanPartition{}, err
+		}
+	}
+	return partition.splitPartition(pkgs, pkgTests, param.SplitPackageThreshold), nil
+}
+
 func PkgsForTags(projectDir string, tags []string, param TestParam) ([]string, error) {
 	// tagsMatcher is a matcher that matches the specified tags (or nil if no tags were specified)
 	tagsMatcher, err := matcherForTags(tags, param)
@@ -16,15 +16,17 @@ package testplugin
 
 import (
 	"bytes"
+	"encoding/xml"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/jstemmer/go-junit-report/v2/junit"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func TestRunTestCmdReturnsErrorForBuildFailureWithoutParsedPackage(t *testing.T) {
+func TestRunTestCmdReturnsErrorForBuildFailure(t *testing.T) {
 	tmpDir := t.TempDir()
 	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module testmod\n\ngo 1.21\n"), 0644))
 
@@ -35,10 +37,120 @@ func TestRunTestCmdReturnsErrorForBuildFailureWithoutParsedPackage(t *testing.T)
 	var stdout bytes.Buffer
 	err := RunTestCmd(tmpDir, nil, nil, "", nil, TestParam{}, &stdout)
 
-	require.EqualError(t, err, `"go test" failed and no failing packages were detected in its output: exit status 1`)
+	require.EqualError(t, err, "1 package(s) failed to build:\n\ttestmod/pkgbad\n\t\tpkgbad/pkgbad.go:3:9: undefined: undefined")
 	assert.Contains(t, stdout.String(), "FAIL\ttestmod/pkgbad [build failed]")
 }
 
+func TestRunTestCmdClassifiesBuildFailures(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module testmod\n\ngo 1.21\n"), 0644))
+	for file, content := range map[string]string{
+		"depbad/depbad.go":        "package depbad\n\nfunc F() int { return \"x\" }\n",
+		"usesbad/usesbad_test.go": "package usesbad\n\nimport (\n\t\"testing\"\n\n\t\"testmod/depbad\"\n)\n\nfunc TestF(t *testing.T) { depbad.F() }\n",
+		"vet/vet_test.go":         "package vet\n\nimport (\n\t\"fmt\"\n\t\"testing\"\n)\n\nfunc TestVet(t *testing.T) { fmt.Printf(\"%d\", \"x\") }\n",
+		"setup/setup_test.go":     "package setup\n\nimport (\n\t\"testing\"\n\n\t_ \"testmod/missing\"\n)\n\nfunc TestSetup(t *testing.T) {}\n",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(tmpDir, file)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, file), []byte(content), 0644))
+	}
+
+	var stdout bytes.Buffer
+	junitOutput := filepath.Join(tmpDir, "junit.xml")
+	err := RunTestCmd(tmpDir, nil, nil, junitOutput, nil, TestParam{}, &stdout)
+	require.Error(t, err)
+	assert.Regexp(t, `^2 package\(s\) failed to build:
+	testmod/depbad
+		depbad/depbad.go:3:23: .+
+	testmod/usesbad \(dependency testmod/depbad failed to build\)
+1 package\(s\) failed vet checks:
+	testmod/vet
+		vet/vet_test.go:8:42: fmt.Printf format %d has arg "x" of wrong type string
+1 package\(s\) failed setup:
+	testmod/setup
+		setup/setup_test.go:6:2: .+$`, err.Error())
+
+	content, err := os.ReadFile(junitOutput)
+	require.NoError(t, err)
+	var suites junit.Testsuites
+	require.NoError(t, xml.Unmarshal(content, &suites))
+	errorTypes := make(map[string]string)
+	for _, suite := range suites.Suites {
+		require.Len(t, suite.Testcases, 1, suite.Name)
+		require.NotNil(t, suite.Testcases[0].Error, suite.Name)
+		errorTypes[suite.Name] = suite.Testcases[0].Name + " " + suite.Testcases[0].Error.Type
+	}
+	assert.Equal(t, map[string]string{
+		"testmod/depbad":  "[build failed] build",
+		"testmod/usesbad": "[build failed] build",
+		"testmod/vet":     "[vet failed] vet",
+		"testmod/setup":   "[setup failed] setup",
+	}, errorTypes)
+}
+
+func TestRunTestCmdConsoleOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module testmod\n\ngo 1.21\n"), 0644))
+	pkgDir := filepath.Join(tmpDir, "pkg")
+	require.NoError(t, os.MkdirAll(pkgDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(pkgDir, "pkg_test.go"), []byte(`package pkg
+
+import "testing"
+
+func TestPass(t *testing.T) {
+	t.Log("output of passing test")
+}
+
+func TestFail(t *testing.T) {
+	t.Error("output of failing test")
+}
+`), 0644))
+
+	for _, tc := range []struct {
+		name          string
+		testArgs      []string
+		consoleOutput ConsoleOutput
+		wantVerbose   bool
+	}{
+		{
+			name: "JUnit output does not make the console verbose",
+		},
+		{
+			name:        "verbose flag",
+			testArgs:    []string{"-v"},
+			wantVerbose: true,
+		},
+		{
+			name:          "verbose console output",
+			consoleOutput: ConsoleOutputVerbose,
+			wantVerbose:   true,
+		},
+		{
+			name:          "failures console output overrides verbose flag",
+			testArgs:      []string{"-v"},
+			consoleOutput: ConsoleOutputFailures,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			junitOutput := filepath.Join(t.TempDir(), "junit.xml")
+			err := RunTestCmd(tmpDir, tc.testArgs, nil, junitOutput, nil, TestParam{ConsoleOutput: tc.consoleOutput}, &stdout)
+			require.Error(t, err)
+
+			assert.Contains(t, stdout.String(), "output of failing test")
+			assert.Contains(t, stdout.String(), "FAIL\ttestmod/pkg")
+			if tc.wantVerbose {
+				assert.Contains(t, stdout.String(), "output of passing test")
+			} else {
+				assert.NotContains(t, stdout.String(), "output of passing test")
+			}
+
+			content, err := os.ReadFile(junitOutput)
+			require.NoError(t, err)
+			assert.Contains(t, string(content), "output of passing test")
+		})
+	}
+}
+
 func TestPkgsToTest(t *testing.T) {
 	// Create a temp directory with some Go packages for testing
 	tmpDir := t.TempDir()
@@ -116,6 +228,21 @@ func TestPkgsToTestEmptyPartition(t *testing.T) {
 	assert.Empty(t, pkgs)
 }
 
+func TestRunTestCmdEmptyPartition(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module testmod\n\ngo 1.21\n"), 0644))
+	pkgDir := filepath.Join(tmpDir, "a")
+	require.NoError(t, os.MkdirAll(pkgDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(pkgDir, "foo.go"), []byte("package foo\n"), 0644))
+
+	var stdout bytes.Buffer
+	junitOutput := filepath.Join(tmpDir, "junit.xml")
+	err := RunTestCmd(tmpDir, nil, nil, junitOutput, &Partition{Index: 1, Total: 2}, TestParam{}, &stdout)
+	require.NoError(t, err)
+	assert.Equal(t, "partition 1 of 2 has no packages to test\n", stdout.String())
+	assert.FileExists(t, junitOutput)
+}
+
 func TestPkgsToTestNoPackages(t *testing.T) {
 	// Create a temp directory with no Go packages
 	tmpDir := t.TempDir()
@@ -128,3 +255,40 @@ func TestPkgsToTestNoPackages(t *testing.T) {
 	require.NoError(t, err)
 	assert.Empty(t, pkgs)
 }
+
+func TestRunTestCmdRetriesFailedTests(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module testmod\n\ngo 1.21\n"), 0644))
+
+	// TestFlaky fails the first time it is run and TestBroken always fails
+	pkgDir := filepath.Join(tmpDir, "pkg")
+	require.NoError(t, os.MkdirAll(pkgDir, 0755))
+	markerFile := filepath.Join(tmpDir, "marker")
+	require.NoError(t, os.WriteFile(filepath.Join(pkgDir, "pkg_test.go"), []byte(`package pkg
+
+import (
+	"os"
+	"testing"
+)
+
+func TestFlaky(t *testing.T) {
+	if _, err := os.Stat(`+"`"+markerFile+"`"+`); err != nil {
+		_ = os.WriteFile(`+"`"+markerFile+"`"+`, nil, 0644)
+		t.Fatal("first attempt fails")
+	}
+}
+
+func TestBroken(t *testing.T) {
+	t.Fatal("always fails")
+}
+
+func TestPass(t *testing.T) {}
+`), 0644))
+
+	var stdout bytes.Buffer
+	err := RunTestCmd(tmpDir, nil, nil, "", nil, TestParam{Retries: 2}, &stdout)
+	require.EqualError(t, err, "1 package(s) had failing tests after 2 retries:\n\ttestmod/pkg\n\t\ttestmod/pkg.TestBroken: pkg/pkg_test.go:16")
+	assert.Contains(t, stdout.String(), "Retrying 2 failed test(s) in 1 package(s) (attempt 1 of 2)\n")
+	assert.Contains(t, stdout.String(), "Retrying 1 failed test(s) in 1 package(s) (attempt 2 of 2)\n")
+	assert.Contains(t, stdout.String(), "1 flaky test(s) passed on retry:\n\ttestmod/pkg.TestFlaky\n")
+}
@@ -0,0 +1,166 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/junit"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/modfile"
+)
+
+// timingsFileVersion is the version of the format of the timings file written by writeTimings.
+const timingsFileVersion = 1
+
+// timingsFile is the format of the timings file written by writeTimings.
+type timingsFile struct {
+	Version int `json:"version"`
+	// Packages contains the duration in seconds of testing each package keyed by the import path of the package.
+	Packages map[string]float64 `json:"packages"`
+}
+
+// ReadPartitionTimings reads the durations of testing packages from the provided files, which may be JUnit XML reports
+// or timings files written by the "--timings-output" flag. Returns the durations keyed by the path of the package
+// relative to the project directory (in the format returned by PkgsToTest). If a package appears in multiple files or
+// in multiple testsuites of a JUnit report (which occurs when the reports of different partitions are provided), its
+// durations are summed.
+func ReadPartitionTimings(projectDir string, files []string) (map[string]time.Duration, error) {
+	modPath, err := modulePath(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	timings := make(map[string]time.Duration)
+	for _, file := range files {
+		fileTimings, err := readTimingsFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for importPath, duration := range fileTimings {
+			relPath, ok := relPkgPath(modPath, importPath)
+			if !ok {
+				// packages that are not in the module are not tested, so their timings are not relevant
+				continue
+			}
+			timings[relPath] += duration
+		}
+	}
+	return timings, nil
+}
+
+// readTimingsFile returns the durations in the provided JUnit XML report or timings file keyed by import path.
+func readTimingsFile(file string) (map[string]time.Duration, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read timings file")
+	}
+	timings := make(map[string]time.Duration)
+	if trimmed := bytes.TrimSpace(content); bytes.HasPrefix(trimmed, []byte("{")) {
+		var tf timingsFile
+		if err := json.Unmarshal(trimmed, &tf); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal timings file %s as JSON", file)
+		}
+		if tf.Version != timingsFileVersion {
+			return nil, errors.Errorf("unsupported version of timings file %s: %d", file, tf.Version)
+		}
+		for pkg, seconds := range tf.Packages {
+			timings[pkg] += secondsDuration(seconds)
+		}
+		return timings, nil
+	}
+
+	var suites junit.Testsuites
+	if err := xml.Unmarshal(content, &suites); err != nil {
+		// a report may consist of a single testsuite
+		var suite junit.Testsuite
+		if suiteErr := xml.Unmarshal(content, &suite); suiteErr != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal timings file %s as JUnit XML", file)
+		}
+		suites.Suites = []junit.Testsuite{suite}
+	}
+	for _, suite := range suites.Suites {
+		seconds, err := strconv.ParseFloat(suite.Time, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid time for testsuite %s in JUnit report %s", suite.Name, file)
+		}
+		timings[suite.Name] += secondsDuration(seconds)
+	}
+	return timings, nil
+}
+
+// writeTimings writes the durations of the packages in the provided results to the provided file.
+func writeTimings(file string, results *testResults) error {
+	tf := timingsFile{
+		Version:  timingsFileVersion,
+		Packages: make(map[string]float64, len(results.pkgs)),
+	}
+	for _, pkg := range results.pkgs {
+		tf.Packages[pkg.Name] = pkg.Elapsed.Seconds()
+	}
+	content, err := json.MarshalIndent(tf, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal timings")
+	}
+	if err := os.WriteFile(file, append(content, '\n'), 0644); err != nil {
+		return errors.Wrapf(err, "failed to write timings file")
+	}
+	return nil
+}
+
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// modulePath returns the module path declared in the go.mod file in the provided project directory.
+func modulePath(projectDir string) (string, error) {
+	goModFile := filepath.Join(projectDir, "go.mod")
+	content, err := os.ReadFile(goModFile)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read %s", goModFile)
+	}
+	modPath := modfile.ModulePath(content)
+	if modPath == "" {
+		return "", errors.Errorf("no module path declared in %s", goModFile)
+	}
+	return modPath, nil
+}
+
+// importPkgPath returns the import path of the package with the provided path relative to the module root (in the
+// format returned by PkgsToTest). It is the inverse of relPkgPath.
+func importPkgPath(modPath, relPath string) string {
+	if rest := strings.TrimPrefix(relPath, "./"); rest != "." {
+		return modPath + "/" + rest
+	}
+	return modPath
+}
+
+// relPkgPath returns the path relative to the module root (in the format returned by PkgsToTest) of the package with
+// the provided import path. Returns false if the package is not in the module.
+func relPkgPath(modPath, importPath string) (string, bool) {
+	if importPath == modPath {
+		return "./.", true
+	}
+	if rest, ok := strings.CutPrefix(importPath, modPath+"/"); ok {
+		return "./" + rest, true
+	}
+	return "", false
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPartitionTimings(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module testmod\n\ngo 1.21\n"), 0644))
+
+	junitFile := filepath.Join(tmpDir, "junit.xml")
+	require.NoError(t, os.WriteFile(junitFile, []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="2">
+	<testsuite name="testmod" tests="1" failures="0" errors="0" id="0" time="0.500"></testsuite>
+	<testsuite name="testmod/foo" tests="1" failures="0" errors="0" id="1" time="2.250"></testsuite>
+	<testsuite name="github.com/other/module" tests="1" failures="0" errors="0" id="2" time="9.000"></testsuite>
+</testsuites>
+`), 0644))
+
+	results := newTestResults()
+	results.process(testEvent{Action: actionPass, Package: "testmod/foo", Elapsed: 1})
+	results.process(testEvent{Action: actionPass, Package: "testmod/bar", Elapsed: 3.5})
+	timingsFile := filepath.Join(tmpDir, "timings.json")
+	require.NoError(t, writeTimings(timingsFile, results))
+
+	got, err := ReadPartitionTimings(tmpDir, []string{junitFile, timingsFile})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Duration{
+		"./.":   500 * time.Millisecond,
+		"./foo": 3250 * time.Millisecond,
+		"./bar": 3500 * time.Millisecond,
+	}, got)
+}